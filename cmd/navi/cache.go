@@ -0,0 +1,128 @@
+package navi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/go-navi/navi/internal/logger"
+	"github.com/go-navi/navi/internal/watcher"
+)
+
+// getCacheDir returns the directory storing the fingerprints of commands with `sources`
+func getCacheDir() string {
+	return filepath.Join(applicationRootPath, ".navi", "cache")
+}
+
+// runCacheCleanCommand removes the stored fingerprints and exits
+func runCacheCleanCommand() {
+	cacheDir := getCacheDir()
+
+	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
+		logger.Info("Cache is already empty")
+		os.Exit(0)
+	}
+
+	if err := os.RemoveAll(cacheDir); err != nil {
+		logger.Error("Failed to clean cache directory `%s`: %v", cacheDir, err)
+		os.Exit(1)
+	}
+
+	logger.Info("Removed cache directory: %s", cacheDir)
+	os.Exit(0)
+}
+
+// executeWithCache runs the command unless its sources, command and environment are unchanged
+// since its last successful execution and its outputs exist
+func (cmd *ProjectCommand) executeWithCache(ctx Ctx) error {
+	fingerprintPath := cmd.getFingerprintPath()
+
+	// Sources changed during the execution make the next one run again
+	fingerprint, err := cmd.computeFingerprint()
+	if err != nil {
+		return err
+	}
+
+	// Up to date commands are skipped with their hooks when executed
+	storedFingerprint, _ := os.ReadFile(fingerprintPath)
+	cmd.UpToDate = !forceExecution && string(storedFingerprint) == fingerprint && cmd.hasOutputs()
+
+	if err := cmd.executeOneTime(ctx, nil); err != nil {
+		os.Remove(fingerprintPath)
+		return err
+	}
+
+	if cmd.Skipped {
+		return nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(fingerprintPath), 0755); err == nil {
+		err = os.WriteFile(fingerprintPath, []byte(fingerprint), 0644)
+	}
+
+	if err != nil {
+		logger.WarnWithPrefix(cmd.GetLogPrefix(), "Failed to store the fingerprint of the sources: %v", err)
+	}
+
+	return nil
+}
+
+// getFingerprintPath returns the file storing the fingerprint of the command
+func (cmd *ProjectCommand) getFingerprintPath() string {
+	key := sha256.Sum256([]byte(cmd.Identifier + "\x00" + cmd.Dir))
+	return filepath.Join(getCacheDir(), hex.EncodeToString(key[:8]))
+}
+
+// computeFingerprint hashes the resolved command, its environment and the content of its sources
+func (cmd *ProjectCommand) computeFingerprint() (string, error) {
+	hash := sha256.New()
+
+	fmt.Fprintf(hash, "dir:%s\x00shell:%s\x00", cmd.Dir, cmd.Shell)
+	for _, command := range cmd.CommandList {
+		fmt.Fprintf(hash, "run:%s\x00", command)
+	}
+
+	envVars := slices.Clone(cmd.EnvVars)
+	slices.Sort(envVars)
+	for _, envVar := range envVars {
+		fmt.Fprintf(hash, "env:%s\x00", envVar)
+	}
+
+	sourceFiles, err := watcher.FindMatchingFiles(watcher.FilePatterns{Include: cmd.Sources})
+	if err != nil {
+		return "", fmt.Errorf("Failed to read the sources of command `%s`: %v", cmd.Identifier, err)
+	}
+
+	for _, sourceFile := range sourceFiles {
+		file, err := os.Open(sourceFile)
+		if err != nil {
+			return "", fmt.Errorf("Failed to read the sources of command `%s`: %v", cmd.Identifier, err)
+		}
+
+		fmt.Fprintf(hash, "file:%s\x00", sourceFile)
+		_, err = io.Copy(hash, file)
+		file.Close()
+
+		if err != nil {
+			return "", fmt.Errorf("Failed to read the sources of command `%s`: %v", cmd.Identifier, err)
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// hasOutputs checks that every `outputs` pattern matches at least one file
+func (cmd *ProjectCommand) hasOutputs() bool {
+	for _, pattern := range cmd.Outputs {
+		files, err := watcher.FindMatchingFiles(watcher.FilePatterns{Include: []string{pattern}})
+		if err != nil || len(files) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
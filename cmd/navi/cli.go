@@ -2,9 +2,11 @@ package navi
 
 import (
 	"fmt"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/go-navi/navi/internal/logger"
 	"github.com/go-navi/navi/internal/term"
@@ -48,6 +50,7 @@ type State struct {
 	MoreDefAbove            bool   // Whether there is more definition content above current view
 	MoreDefBelow            bool   // Whether there is more definition content below current view
 	CommandArgs             string // Current command arguments text
+	ActiveParamIndex        int    // Index of the parameter being edited in the arguments modal
 }
 
 var (
@@ -57,6 +60,9 @@ var (
 	selectedItemDefinition []string       // Definition lines for selected item
 	commandArgModalLines   []string       // Lines of text for command arguments modal
 	commandArgsModalWidth  int            // Width of command arguments modal
+	commandParams          []CommandParam // Parameters declared by the command in the arguments modal
+	commandParamValues     []string       // Values entered for each declared parameter
+	activeParamCursorLine  int            // Modal line where the active parameter input ends
 )
 
 // HasChanges checks if current state differs from previous state
@@ -78,7 +84,8 @@ func (s *State) HasChanges() bool {
 		s.MoreListItemsBelow != prevState.MoreListItemsBelow ||
 		s.MoreDefAbove != prevState.MoreDefAbove ||
 		s.MoreDefBelow != prevState.MoreDefBelow ||
-		s.CommandArgs != prevState.CommandArgs
+		s.CommandArgs != prevState.CommandArgs ||
+		s.ActiveParamIndex != prevState.ActiveParamIndex
 }
 
 // UpdateState copies current state to previous state
@@ -101,6 +108,7 @@ func (s *State) UpdateState() {
 	prevState.MoreDefAbove = s.MoreDefAbove
 	prevState.MoreDefBelow = s.MoreDefBelow
 	prevState.CommandArgs = s.CommandArgs
+	prevState.ActiveParamIndex = s.ActiveParamIndex
 }
 
 // highlightMatches returns a string with matching characters highlighted
@@ -161,36 +169,7 @@ func stripFlags(runnerName string) string {
 
 // getKeyPriority returns sort priority for configuration keys
 func getKeyPriority(key string) int {
-	orderedKeys := []string{
-		"dir",
-		"shell",
-		"watch",
-		"include",
-		"exclude",
-		"dotenv",
-		"env",
-		"pre",
-		"run",
-		"post",
-		"after",
-		"success",
-		"failure",
-		"change",
-		"always",
-		"cmds",
-		"cmd",
-		"name",
-		"serial",
-		"dependent",
-		"delay",
-		"restart",
-		"retries",
-		"interval",
-		"condition",
-		"awaits",
-		"ports",
-		"timeout",
-	}
+	orderedKeys := getOrderedConfigKeyNames()
 
 	for i, orderedKey := range orderedKeys {
 		if key == orderedKey {
@@ -201,12 +180,15 @@ func getKeyPriority(key string) int {
 	return len(orderedKeys)
 }
 
-// formatConfigData transforms configuration data into formatted display lines
+// formatConfigData transforms configuration data into formatted display lines,
+// marking the values set by overlay files
 func formatConfigData(
 	data any,
 	indent int,
 	itemType int,
 	parentKey string,
+	path string,
+	overlayValues map[string]string,
 ) (defLines []string) {
 	termUI := term.NewTermUI()
 	indentSpace := "  "
@@ -251,6 +233,18 @@ func formatConfigData(
 
 		for _, k := range keys {
 			val := v[k]
+			keyPath := joinConfigPath(path, k)
+
+			// Display maps of strings, like project `env`, key by key
+			if convertedVal, isMap := convertToAnyMap(val); isMap {
+				val = convertedVal
+			}
+
+			// Show the overlay file setting the value as a trailing comment
+			overlayMark := ""
+			if overlayFile, isOverlay := overlayValues[keyPath]; isOverlay {
+				overlayMark = termUI.Store().DarkGray().Print("  # " + filepath.Base(overlayFile)).PrevColor().GetStored()
+			}
 
 			switch val.(type) {
 			case map[string]any, []any:
@@ -260,14 +254,14 @@ func formatConfigData(
 						Store().
 						Repeat(indentSpace, indent).
 						Cyan().Print(k).PrevColor().Print(":").
-						GetStored(),
+						GetStored()+overlayMark,
 				)
 
-				valLines := formatConfigData(val, indent+1, itemType, k)
+				valLines := formatConfigData(val, indent+1, itemType, k, keyPath, overlayValues)
 				defLines = append(defLines, valLines...)
 
 			default:
-				valLines := formatConfigData(val, indent+1, itemType, k)
+				valLines := formatConfigData(val, indent+1, itemType, k, keyPath, overlayValues)
 
 				if len(valLines) == 1 {
 					defLines = append(
@@ -276,7 +270,7 @@ func formatConfigData(
 							Store().
 							Repeat(indentSpace, indent).
 							Cyan().Print(k).PrevColor().Print(": "+strings.TrimSpace(valLines[0])).
-							GetStored(),
+							GetStored()+overlayMark,
 					)
 				} else {
 					defLines = append(
@@ -285,7 +279,7 @@ func formatConfigData(
 							Store().
 							Repeat(indentSpace, indent).
 							Cyan().Print(k).PrevColor().Print(":").
-							GetStored(),
+							GetStored()+overlayMark,
 					)
 
 					defLines = append(defLines, valLines...)
@@ -295,7 +289,7 @@ func formatConfigData(
 
 	case []any:
 		for _, item := range v {
-			itemLines := formatConfigData(item, indent+1, itemType, parentKey)
+			itemLines := formatConfigData(item, indent+1, itemType, parentKey, path, overlayValues)
 
 			if len(itemLines) > 0 {
 				for idx, line := range itemLines {
@@ -379,12 +373,20 @@ func renderTitle() {
 	modalShrank := prevState.CommandModalInputHeight > state.CommandModalInputHeight
 
 	if resized || modalClosed || modalShrank {
+		title := "Navi CLI"
+		if activeProfile != "" {
+			title += " [" + activeProfile + "]"
+		}
+
+		// Keep the title centered when the profile is displayed
+		padding := max(state.ListPanelWidth-3-(utf8.RuneCountInString(title)-8)/2, 0)
+
 		termUI.
 			Cursor(1, 1).
 			ClearLine().
-			Repeat(" ", state.ListPanelWidth-3).
+			Repeat(" ", padding).
 			Cyan().
-			Print("Navi CLI").
+			Print(title).
 			PrevColor()
 	}
 }
@@ -873,7 +875,8 @@ func renderCommandArgsModal() {
 	resized := state.TermWidth != prevState.TermWidth ||
 		state.TermHeight != prevState.TermHeight
 
-	commandArgsChanged := state.CommandArgs != prevState.CommandArgs
+	commandArgsChanged := state.CommandArgs != prevState.CommandArgs ||
+		state.ActiveParamIndex != prevState.ActiveParamIndex
 	onCommandArgsModalChanged := state.OnCommandArgsModal != prevState.OnCommandArgsModal
 
 	if resized || commandArgsChanged || onCommandArgsModalChanged {
@@ -891,9 +894,14 @@ func renderCommandArgsModal() {
 		modalY := ((state.TermHeight - modalHeight) / 2) + 1 // +1 to adjust centering
 		modalHeight += len(commandArgModalLines) - 1         // Adjust height to fit the prompt lines
 
+		modalTitle := "Enter Command Arguments"
+		if len(commandParams) > 0 {
+			modalTitle = "Enter Command Parameters"
+		}
+
 		if drawModal {
 			termUI.DrawInputModal(
-				"Enter Command Arguments",
+				modalTitle,
 				modalX,
 				modalY,
 				modalWidth,
@@ -908,6 +916,14 @@ func renderCommandArgsModal() {
 				Cursor(modalX+2, modalY+2+i).
 				Print(line)
 		}
+
+		// Place the cursor at the end of the parameter being edited
+		if len(commandParams) > 0 && activeParamCursorLine < len(commandArgModalLines) {
+			termUI.Cursor(
+				modalX+2+term.CountPrintableChars(commandArgModalLines[activeParamCursorLine]),
+				modalY+2+activeParamCursorLine,
+			)
+		}
 	}
 }
 
@@ -988,10 +1004,11 @@ func updateSelectedItemDefinition() (defLines []string, errMsg string) {
 	}
 
 	var configData any
-	var description string
+	var description, section string
 
 	switch selectedMenuItem.Type {
 	case COMMAND_TYPE:
+		section = "commands"
 		if yamlConfig.Commands != nil {
 			comments, hasComment := commentsMap["$.commands."+selectedMenuItem.Name]
 
@@ -1014,6 +1031,7 @@ func updateSelectedItemDefinition() (defLines []string, errMsg string) {
 
 	case PROJ_COMMAND_TYPE:
 		projectName, cmdName := selectedMenuItem.Name, selectedMenuItem.CmdName
+		section = "projects"
 
 		if project, exists := yamlConfig.Projects[projectName]; exists {
 			projectCopy := project.getRawProject()
@@ -1047,6 +1065,7 @@ func updateSelectedItemDefinition() (defLines []string, errMsg string) {
 		}
 
 	case RUNNER_TYPE:
+		section = "runners"
 		if yamlConfig.Runners != nil {
 			comments, hasComment := commentsMap["$.runners."+selectedMenuItem.Name]
 
@@ -1072,7 +1091,7 @@ func updateSelectedItemDefinition() (defLines []string, errMsg string) {
 		return nil, "No configuration found for " + selectedMenuItem.Name
 	}
 
-	defLines = formatConfigData(configData, 0, selectedMenuItem.Type, "")
+	defLines = formatConfigData(configData, 0, selectedMenuItem.Type, "", section, yamlConfig.overlayValues)
 
 	if strings.TrimSpace(description) != "" {
 		wrapWidth := state.DefPanelWidth
@@ -1162,6 +1181,116 @@ func getInputModalPromptLines(inputLabel, inputText string, modalWidth int) []st
 	return promptLines
 }
 
+// getParamModalLines formats one input line per declared parameter, returning the
+// lines and the index of the line where the active input ends
+func getParamModalLines(params []CommandParam, values []string, activeIndex, modalWidth int) ([]string, int) {
+	contentWidth := modalWidth - 4 // account for borders and padding
+	lines := []string{}
+	cursorLine := 0
+
+	for i, param := range params {
+		label := param.Name
+		if len(param.Allowed) > 0 {
+			label += " (" + strings.Join(param.Allowed, "|") + ")"
+		}
+
+		if param.Required && !param.HasDefault {
+			label += "*"
+		}
+
+		label += ": "
+
+		if param.Description != "" || param.HasDefault {
+			hint := param.Description
+			if param.HasDefault {
+				hint = strings.TrimSpace(hint + " [default: " + param.Default + "]")
+			}
+
+			hintText := term.NewTermUI().
+				WrapToWidth(contentWidth).
+				DarkGray().Print("  " + hint).PrevColor().
+				GetFormattedText()
+
+			lines = append(lines, strings.Split(hintText, "\n")...)
+		}
+
+		prefix := "  "
+		if i == activeIndex {
+			prefix = "> "
+		}
+
+		inputText := term.NewTermUI().
+			WrapToWidth(contentWidth).
+			Cyan().Print(prefix + label).PrevColor().
+			Print(values[i]).
+			GetFormattedText()
+
+		inputLines := strings.Split(inputText, "\n")
+
+		// ensure proper cursor positioning
+		if term.CountPrintableChars(inputLines[len(inputLines)-1]) == contentWidth {
+			inputLines = append(inputLines, "")
+		}
+
+		lines = append(lines, inputLines...)
+
+		if i == activeIndex {
+			cursorLine = len(lines) - 1
+		}
+	}
+
+	return lines, cursorLine
+}
+
+// getMenuItemParams returns the parameters declared by the command of a menu item
+func getMenuItemParams(menuItem *NaviMenuItem) []CommandParam {
+	yamlConfig, _, err := getYamlConfiguration(false)
+	if err != nil {
+		return nil
+	}
+
+	var commandRaw any
+	var projectName, commandName string
+	var projectVars map[string]any
+	switch menuItem.Type {
+	case COMMAND_TYPE:
+		commandName = menuItem.Name
+		commandRaw = yamlConfig.Commands[commandName]
+	case PROJ_COMMAND_TYPE:
+		projectName, commandName = menuItem.Name, menuItem.CmdName
+		commandRaw = yamlConfig.Projects[projectName].Cmds[commandName]
+		projectVars = yamlConfig.Projects[projectName].Vars
+	}
+
+	params, err := parseCommandParams(commandRaw, menuItem.Id)
+	if err != nil {
+		return nil
+	}
+
+	// Display defaults with their templates rendered
+	scope, err := newTemplateScope(projectName, commandName).withVars(yamlConfig.Vars)
+	if err == nil {
+		scope, err = scope.withVars(projectVars)
+	}
+
+	if err == nil {
+		for i := range params {
+			if rendered, err := scope.renderTemplate(params[i].Default); err == nil {
+				params[i].Default = rendered
+			}
+		}
+	}
+
+	return params
+}
+
+// switchActiveParam stores the value being edited and moves to another parameter
+func switchActiveParam(offset int) {
+	commandParamValues[state.ActiveParamIndex] = state.CommandArgs
+	state.ActiveParamIndex = (state.ActiveParamIndex + offset + len(commandParams)) % len(commandParams)
+	state.CommandArgs = commandParamValues[state.ActiveParamIndex]
+}
+
 // CheckState updates state based on current conditions and constraints
 func (s *State) CheckState() {
 	// update terminal size
@@ -1300,11 +1429,24 @@ func (s *State) CheckState() {
 			commandArgsModalWidth = 10
 		}
 
-		commandArgModalLines = getInputModalPromptLines(
-			commandArgsInputLabel,
-			s.CommandArgs,
-			commandArgsModalWidth,
-		)
+		if len(commandParams) > 0 {
+			paramValues := make([]string, len(commandParamValues))
+			copy(paramValues, commandParamValues)
+			paramValues[s.ActiveParamIndex] = s.CommandArgs
+
+			commandArgModalLines, activeParamCursorLine = getParamModalLines(
+				commandParams,
+				paramValues,
+				s.ActiveParamIndex,
+				commandArgsModalWidth,
+			)
+		} else {
+			commandArgModalLines = getInputModalPromptLines(
+				commandArgsInputLabel,
+				s.CommandArgs,
+				commandArgsModalWidth,
+			)
+		}
 
 		s.CommandModalInputHeight = len(commandArgModalLines)
 	} else {
@@ -1330,6 +1472,9 @@ func getArgs() ([]string, error) {
 		switch inputKey {
 		case term.KEY_UP:
 			if state.OnCommandArgsModal {
+				if len(commandParams) > 0 {
+					switchActiveParam(-1)
+				}
 				continue
 			}
 
@@ -1341,6 +1486,9 @@ func getArgs() ([]string, error) {
 
 		case term.KEY_DOWN:
 			if state.OnCommandArgsModal {
+				if len(commandParams) > 0 {
+					switchActiveParam(1)
+				}
 				continue
 			}
 
@@ -1400,6 +1548,18 @@ func getArgs() ([]string, error) {
 			if selectedMenuItem != nil && state.OnCommandArgsModal {
 				args := []string{selectedMenuItem.Id}
 
+				if len(commandParams) > 0 {
+					commandParamValues[state.ActiveParamIndex] = state.CommandArgs
+
+					for i, param := range commandParams {
+						if value := strings.TrimSpace(commandParamValues[i]); value != "" {
+							args = append(args, "--"+param.Name+"="+value)
+						}
+					}
+
+					return args, nil
+				}
+
 				if state.CommandArgs != "" {
 					commandArgs, err := shellquote.Split(state.CommandArgs)
 					if err != nil {
@@ -1431,6 +1591,11 @@ func getArgs() ([]string, error) {
 
 		case term.KEY_TAB, term.KEY_SHIFT_TAB:
 			if state.OnCommandArgsModal {
+				if len(commandParams) > 0 && inputKey == term.KEY_TAB {
+					switchActiveParam(1)
+				} else if len(commandParams) > 0 {
+					switchActiveParam(-1)
+				}
 				continue
 			}
 
@@ -1458,6 +1623,9 @@ func getArgs() ([]string, error) {
 			if selectedMenuItem != nil &&
 				(selectedMenuItem.Type == PROJ_COMMAND_TYPE || selectedMenuItem.Type == COMMAND_TYPE) {
 				state.OnCommandArgsModal = true
+				state.ActiveParamIndex = 0
+				commandParams = getMenuItemParams(selectedMenuItem)
+				commandParamValues = make([]string, len(commandParams))
 			}
 
 		case term.TEST_SNAPSHOT:
@@ -1562,6 +1730,8 @@ func startCLI() ([]string, error) {
 	filteredMenuItems = nil
 	selectedItemDefinition = nil
 	commandArgModalLines = nil
+	commandParams = nil
+	commandParamValues = nil
 
 	if getArgsErr != nil {
 		return nil, getArgsErr
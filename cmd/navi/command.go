@@ -5,14 +5,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"math/rand"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
@@ -26,11 +30,23 @@ import (
 var suppressNewAfterCommands = false                             // Flag to prevent new after commands from starting
 var logExecId = "naviLogId" + strconv.Itoa(rand.Intn(100000000)) // ID for command execution logs
 
+// Time given to a timed out command to stop before it is killed
+const timeoutGracePeriod = 5 * time.Second
+
+// Time given to the output of a finished command to be read when other processes still hold it
+const outputWaitDelay = 1 * time.Second
+
+// Environment variable enabling colors in the output of commands
+const forceColorEnvVar = "FORCE_COLOR=1"
+
 // execute runs a command with automatic watch mode detection
 func (cmd *ProjectCommand) execute(ctx Ctx) error {
 	if hasFilesToWatch(cmd.WatchPatterns) {
 		return cmd.executeWithFileWatcher(ctx)
 	}
+	if len(cmd.Sources) > 0 {
+		return cmd.executeWithCache(ctx)
+	}
 	return cmd.executeOneTime(ctx, nil)
 }
 
@@ -43,6 +59,11 @@ func (cmd *ProjectCommand) executeAfterHooks(executionErr error, ctx Ctx, isAfte
 	cmd.AfterExecuted = true
 	defer processWg.Done()
 
+	// Skipped commands don't run their after hooks
+	if cmd.Skipped {
+		return nil
+	}
+
 	// Helper function to execute a set of after commands with common logic
 	executeAfterCommandSet := func(afterCommandSet *ProjectCommand, isProjectLevel bool) error {
 		if afterCommandSet == nil {
@@ -132,7 +153,9 @@ func (cmd *ProjectCommand) executeOneTime(ctx Ctx, watchData *ExecuteWatchData)
 	}
 
 	execError := cmd.executeCommand(ctx, watchData, false, true)
-	if execError == nil {
+	if execError == nil && cmd.ToleratedFailures {
+		logger.WarnWithPrefix(cmd.GetLogPrefix(), "Command(s) completed with tolerated failures")
+	} else if execError == nil && !cmd.Skipped {
 		logger.InfoWithPrefix(cmd.GetLogPrefix(), "Command(s) completed successfully")
 	}
 
@@ -158,6 +181,28 @@ func (cmd *ProjectCommand) executeCommand(ctx Ctx, watchData *ExecuteWatchData,
 			cmd.ProjPreCommand != nil || cmd.ProjPostCommand != nil
 	}
 
+	// Skip the command and its hooks when its `if` condition is not met
+	skipped, err := cmd.skipIfConditionNotMet(ctx, cmd.Condition)
+	if err != nil {
+		return err
+	}
+
+	// Cached commands whose sources and outputs didn't change are skipped the same way
+	if !skipped && isMainCommand && !isAfterCmd && cmd.UpToDate {
+		logger.InfoWithPrefix(cmd.GetLogPrefix(), "Sources and outputs are up to date. Skipping execution")
+		skipped = true
+	}
+
+	if isMainCommand && !isAfterCmd {
+		cmd.Skipped = skipped
+	}
+
+	cmd.ToleratedFailures = false
+
+	if skipped {
+		return nil
+	}
+
 	// Run pre-hooks first
 	if err := cmd.executePreHooks(ctx, watchData, isAfterCmd); err != nil {
 		return err
@@ -169,7 +214,7 @@ func (cmd *ProjectCommand) executeCommand(ctx Ctx, watchData *ExecuteWatchData,
 	}
 
 	// Execute commands
-	if err := cmd.executeSingleCommand(ctx, watchData, isAfterCmd, cmd.CommandList); err != nil {
+	if err := cmd.executeSteps(ctx, watchData, isAfterCmd); err != nil {
 		return err
 	}
 
@@ -184,8 +229,9 @@ func (cmd *ProjectCommand) executePreHooks(ctx Ctx, watchData *ExecuteWatchData,
 		cmd.ProjPreCommand.copyLogConfiguration(cmd)
 		logger.InfoWithPrefix(cmd.GetLogPrefix(), "Running project-level `pre` command...")
 		if err := cmd.ProjPreCommand.executeCommand(ctx, watchData, isAfterCmd, false); err != nil {
-			return preserveWatchModeErrorState(err, fmt.Errorf("Project `pre` command failed: %v", err))
+			return preserveWatchModeErrorState(err, fmt.Errorf("Project `pre` command failed: %w", err))
 		}
+		cmd.ToleratedFailures = cmd.ToleratedFailures || cmd.ProjPreCommand.ToleratedFailures
 	}
 
 	// Command-level pre commands run next
@@ -193,8 +239,9 @@ func (cmd *ProjectCommand) executePreHooks(ctx Ctx, watchData *ExecuteWatchData,
 		cmd.PreCommand.copyLogConfiguration(cmd)
 		logger.InfoWithPrefix(cmd.GetLogPrefix(), "Running `pre` command...")
 		if err := cmd.PreCommand.executeCommand(ctx, watchData, isAfterCmd, false); err != nil {
-			return preserveWatchModeErrorState(err, fmt.Errorf("Command `pre` command failed: %v", err))
+			return preserveWatchModeErrorState(err, fmt.Errorf("Command `pre` command failed: %w", err))
 		}
+		cmd.ToleratedFailures = cmd.ToleratedFailures || cmd.PreCommand.ToleratedFailures
 	}
 
 	return nil
@@ -207,8 +254,9 @@ func (cmd *ProjectCommand) executePostHooks(ctx Ctx, watchData *ExecuteWatchData
 		cmd.PostCommand.copyLogConfiguration(cmd)
 		logger.InfoWithPrefix(cmd.GetLogPrefix(), "Running `post` command...")
 		if err := cmd.PostCommand.executeCommand(ctx, watchData, isAfterCmd, false); err != nil {
-			return preserveWatchModeErrorState(err, fmt.Errorf("Command `post` command failed: %v", err))
+			return preserveWatchModeErrorState(err, fmt.Errorf("Command `post` command failed: %w", err))
 		}
+		cmd.ToleratedFailures = cmd.ToleratedFailures || cmd.PostCommand.ToleratedFailures
 	}
 
 	// Project-level post commands run last
@@ -216,8 +264,9 @@ func (cmd *ProjectCommand) executePostHooks(ctx Ctx, watchData *ExecuteWatchData
 		cmd.ProjPostCommand.copyLogConfiguration(cmd)
 		logger.InfoWithPrefix(cmd.GetLogPrefix(), "Running project-level `post` command...")
 		if err := cmd.ProjPostCommand.executeCommand(ctx, watchData, isAfterCmd, false); err != nil {
-			return preserveWatchModeErrorState(err, fmt.Errorf("Project `post` command failed: %v", err))
+			return preserveWatchModeErrorState(err, fmt.Errorf("Project `post` command failed: %w", err))
 		}
+		cmd.ToleratedFailures = cmd.ToleratedFailures || cmd.ProjPostCommand.ToleratedFailures
 	}
 
 	return nil
@@ -230,30 +279,116 @@ func (targetCmd *ProjectCommand) copyLogConfiguration(sourceCmd *ProjectCommand)
 	targetCmd.LogPrefixColor = sourceCmd.LogPrefixColor
 }
 
-// executeSingleCommand runs a single system command
-func (cmd *ProjectCommand) executeSingleCommand(ctx Ctx, watchData *ExecuteWatchData, isAfterCmd bool, cmdArgs []string) error {
-	var execLogMap map[string]string
-	var err error
-	var cmdShell = cmd.Shell
+// executeSteps runs the commands of the list, each one as its own process unless they share a shell
+func (cmd *ProjectCommand) executeSteps(ctx Ctx, watchData *ExecuteWatchData, isAfterCmd bool) error {
+	// The timeout covers all the steps of the command
+	var deadline time.Time
+	if cmd.Timeout > 0 {
+		deadline = time.Now().Add(cmd.Timeout)
+	}
 
-	// Prepare command with default shell if not specified
-	if strings.TrimSpace(cmd.Shell) == "" {
-		if runtime.GOOS == "windows" {
-			cmdShell = "cmd" // default windows shell
-		} else {
-			cmdShell = os.Getenv("SHELL") // default unix shell
+	if cmd.SharedShell {
+		return cmd.executeSharedShellSteps(ctx, watchData, isAfterCmd, deadline)
+	}
 
-			if strings.TrimSpace(cmdShell) == "" {
-				if runtime.GOOS == "darwin" {
-					cmdShell = "zsh" // On macOS, try to use zsh
-				} else {
-					cmdShell = "bash" // fallback to bash for other Unix systems
-				}
+	for idx, step := range cmd.CommandList {
+		startTime := time.Now()
+		err := cmd.executeSingleCommand(ctx, watchData, isAfterCmd, []string{step}, deadline)
+
+		var exitErr *exec.ExitError
+		isExitError := errors.As(err, &exitErr)
+
+		if err == nil || isExitError {
+			exitCode := 0
+			if isExitError {
+				exitCode = exitErr.ExitCode()
+			}
+
+			logger.InfoWithPrefix(cmd.GetLogPrefix(), "Finished `%s` in %s with exit code %d",
+				step, time.Since(startTime).Round(time.Millisecond), exitCode)
+		}
+
+		if err != nil {
+			if !isExitError || !cmd.getStepPolicy(idx).toleratesExitCode(exitErr.ExitCode()) {
+				return err
 			}
+			cmd.tolerateStepFailure(step, exitErr.ExitCode())
+		}
+	}
+
+	return nil
+}
+
+// executeSharedShellSteps runs the commands of the list in a single shell, executing separately
+// the steps whose failure is tolerated
+func (cmd *ProjectCommand) executeSharedShellSteps(ctx Ctx, watchData *ExecuteWatchData, isAfterCmd bool, deadline time.Time) error {
+	for _, group := range cmd.getStepGroups() {
+		err := cmd.executeSingleCommand(ctx, watchData, isAfterCmd, group.steps, deadline)
+		if err == nil {
+			continue
 		}
+
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || !group.policy.toleratesExitCode(exitErr.ExitCode()) {
+			return err
+		}
+		cmd.tolerateStepFailure(group.steps[0], exitErr.ExitCode())
+	}
+
+	return nil
+}
+
+// getStepGroups splits the commands of the list into the processes executing them. Commands sharing
+// a shell are grouped, except the ones whose failure is tolerated, which run separately
+func (cmd *ProjectCommand) getStepGroups() []stepGroup {
+	groups := []stepGroup{}
+	pendingSteps := []string{}
+
+	for idx, step := range cmd.CommandList {
+		policy := cmd.getStepPolicy(idx)
+		if cmd.SharedShell && !policy.toleratesFailures() {
+			pendingSteps = append(pendingSteps, step)
+			continue
+		}
+
+		// Steps stopping on failure still share a single shell
+		if len(pendingSteps) > 0 {
+			groups = append(groups, stepGroup{steps: pendingSteps})
+			pendingSteps = []string{}
+		}
+
+		groups = append(groups, stepGroup{steps: []string{step}, policy: policy})
+	}
+
+	if len(pendingSteps) > 0 {
+		groups = append(groups, stepGroup{steps: pendingSteps})
 	}
 
-	cmdArgs, execLogMap, err = prepareShellCommands(cmdShell, cmdArgs)
+	return groups
+}
+
+// getStepPolicy returns the failure policy of a step of the list
+func (cmd *ProjectCommand) getStepPolicy(idx int) StepPolicy {
+	if idx < len(cmd.StepPolicies) {
+		return cmd.StepPolicies[idx]
+	}
+	return StepPolicy{}
+}
+
+// tolerateStepFailure records a failed step that lets the command continue
+func (cmd *ProjectCommand) tolerateStepFailure(step string, exitCode int) {
+	logger.WarnWithPrefix(cmd.GetLogPrefix(), "`%s` failed with exit code %d, which is tolerated", step, exitCode)
+	cmd.ToleratedFailures = true
+}
+
+// executeSingleCommand runs a single system command
+func (cmd *ProjectCommand) executeSingleCommand(ctx Ctx, watchData *ExecuteWatchData, isAfterCmd bool, cmdArgs []string, deadline time.Time) error {
+	// Commands running in their own shell, or writing to the terminal directly, print their execution log beforehand
+	if !cmd.printsExecutionLogs() {
+		cmd.printExecutionLog("Executing `" + strings.Join(cmdArgs, " && ") + "`")
+	}
+
+	cmdArgs, execLogMap, err := cmd.getProcessArgs(cmdArgs)
 	if err != nil {
 		return err
 	}
@@ -261,22 +396,28 @@ func (cmd *ProjectCommand) executeSingleCommand(ctx Ctx, watchData *ExecuteWatch
 	// Create the OS command
 	processCmd := exec.CommandContext(ctx.Ctx, cmdArgs[0], cmdArgs[1:]...)
 
-	// Configure process group based on OS
+	// Configure process group based on OS. Interactive commands get the foreground of the
+	// terminal to read from it and receive its signals, until they have finished
 	if runtime.GOOS == "windows" {
 		process.SetupProcessGroup(processCmd)
-	} else {
+	} else if !cmd.Interactive {
 		process.SetupNewProcessGroup(processCmd)
+	} else if process.SetupForegroundProcessGroup(processCmd) {
+		defer process.RestoreForeground()
 	}
 
 	// Set up working directory and environment
 	processCmd.Dir = cmd.Dir
-	processCmd.Env = append(os.Environ(), cmd.EnvVars...)
-	processCmd.Env = append(processCmd.Env, "FORCE_COLOR=1") // Enable colors in output
+	processCmd.Env = cmd.getProcessEnv()
 
 	// Configure output handling
-	waitForOutput, err := cmd.setupCommandOutputHandling(processCmd, execLogMap)
-	if err != nil {
-		return err
+	waitForOutput := func() {}
+	if cmd.Interactive {
+		processCmd.Stdin = os.Stdin
+		processCmd.Stdout = os.Stdout
+		processCmd.Stderr = os.Stderr
+	} else {
+		waitForOutput = cmd.setupCommandOutputHandling(processCmd, execLogMap)
 	}
 
 	// Update watch group if needed
@@ -290,6 +431,8 @@ func (cmd *ProjectCommand) executeSingleCommand(ctx Ctx, watchData *ExecuteWatch
 			watchData.ProcessWatchWg.Done()
 		}
 
+		waitForOutput()
+
 		// Handle special error cases
 		if process.TerminatingProcesses && !isAfterCmd {
 			return ErrProcessTerminated
@@ -313,13 +456,37 @@ func (cmd *ProjectCommand) executeSingleCommand(ctx Ctx, watchData *ExecuteWatch
 		watchData.RunningCmd = processCmd
 	}
 
+	// Terminate the command when its timeout expires, then kill it after a grace period
+	var timedOut atomic.Bool
+	if !deadline.IsZero() {
+		processDone := make(chan struct{})
+		defer close(processDone)
+
+		timeoutTimer := time.AfterFunc(time.Until(deadline), func() {
+			timedOut.Store(true)
+			logger.WarnWithPrefix(cmd.GetLogPrefix(), "Command timed out after %s. Terminating...", formatTimeout(cmd.Timeout))
+			process.TerminateProcess(processCmd)
+
+			select {
+			case <-processDone:
+			case <-time.After(timeoutGracePeriod):
+				process.KillProcessGroup(processCmd)
+			}
+		})
+		defer timeoutTimer.Stop()
+	}
+
 	// Wait for command to complete
-	if err := processCmd.Wait(); err != nil {
+	if err := processCmd.Wait(); err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+		waitForOutput()
+
 		if watchData != nil {
 			watchData.ProcessWatchWg.Done()
 		}
 
-		waitForOutput()
+		if timedOut.Load() {
+			return fmt.Errorf("%w after %s", ErrCommandTimeout, formatTimeout(cmd.Timeout))
+		}
 
 		// Handle special error cases
 		if process.TerminatingProcesses && !isAfterCmd {
@@ -330,15 +497,20 @@ func (cmd *ProjectCommand) executeSingleCommand(ctx Ctx, watchData *ExecuteWatch
 			return ErrWatchModeRestart
 		}
 
-		return fmt.Errorf("The command has failed with exit code %v", err)
+		return fmt.Errorf("The command has failed with exit code %w", err)
 	}
 
 	// Cleanup after successful execution
+	waitForOutput()
+
 	if watchData != nil {
 		watchData.ProcessWatchWg.Done()
 	}
 
-	waitForOutput()
+	// Commands may exit successfully when terminated
+	if timedOut.Load() {
+		return fmt.Errorf("%w after %s", ErrCommandTimeout, formatTimeout(cmd.Timeout))
+	}
 
 	// Check for global termination
 	if process.TerminatingProcesses && !isAfterCmd {
@@ -352,6 +524,45 @@ func (cmd *ProjectCommand) executeSingleCommand(ctx Ctx, watchData *ExecuteWatch
 	return nil
 }
 
+// printExecutionLog prints the log announcing the execution of a command
+func (cmd *ProjectCommand) printExecutionLog(execLog string) {
+	execLog = logger.MaskSecrets(execLog)
+
+	if !utils.IsRunningInTestMode() {
+		colorGreen := "\033[0;32m"
+		colorReset := "\033[0m"
+		execLog = colorGreen + execLog + colorReset
+	}
+
+	if cmd.LogPrefix == "" {
+		fmt.Println(execLog)
+	} else {
+		fmt.Println(cmd.GetLogPrefix() + " " + execLog)
+	}
+}
+
+// getShell returns the shell of the command, or the default shell of the system
+func (cmd *ProjectCommand) getShell() string {
+	if strings.TrimSpace(cmd.Shell) != "" {
+		return cmd.Shell
+	}
+
+	if runtime.GOOS == "windows" {
+		return "cmd" // default windows shell
+	}
+
+	cmdShell := os.Getenv("SHELL") // default unix shell
+	if strings.TrimSpace(cmdShell) == "" {
+		if runtime.GOOS == "darwin" {
+			cmdShell = "zsh" // On macOS, try to use zsh
+		} else {
+			cmdShell = "bash" // fallback to bash for other Unix systems
+		}
+	}
+
+	return cmdShell
+}
+
 // executeWithFileWatcher runs a command with file watching capability
 func (cmd *ProjectCommand) executeWithFileWatcher(parentCtx Ctx) error {
 	logger.InfoWithPrefix(cmd.GetLogPrefix(), "Starting in watch mode")
@@ -437,6 +648,7 @@ func (cmd *ProjectCommand) executeWithFileWatcher(parentCtx Ctx) error {
 			afterErr := cmd.executeAfterHooks(execErr, cmdContext, true)
 			if afterErr != nil {
 				logger.ErrorWithPrefix(cmd.GetLogPrefix(), "Fail during execution of after command(s) in watch mode: %v", afterErr)
+				recordFailure(afterErr)
 				gracefulShutdown(parentCtx, "")
 			}
 
@@ -538,6 +750,8 @@ func getProjectCommand(args []string) (projectCmd *ProjectCommand, projectNotFou
 		return nil, false, fmt.Errorf("Failed to load configuration from YAML file: %v", err)
 	}
 
+	setSecretEnvPatterns(yamlConfig.Secrets)
+
 	projectName, commandName, found, isGlobalCommand, err := findGlobalCommandOrProjectOrProjectCommand(args)
 	if err != nil {
 		return nil, !found, err
@@ -546,6 +760,7 @@ func getProjectCommand(args []string) (projectCmd *ProjectCommand, projectNotFou
 	if isGlobalCommand {
 		mainCommand = yamlConfig.Commands[commandName]
 		commandIdentifier = commandName
+		projectConfig.baseDir = yamlConfig.commandDirs[commandName]
 	} else {
 		projectConfig = yamlConfig.Projects[projectName]
 
@@ -575,17 +790,88 @@ func getProjectCommand(args []string) (projectCmd *ProjectCommand, projectNotFou
 		}
 	}
 
+	// Inherit the settings of extended commands before reading parameters and templates
+	if commandMap, isMap := mainCommand.(map[string]any); isMap {
+		if mainCommand, err = resolveCommandExtends(yamlConfig, commandMap, commandIdentifier, projectName, nil); err != nil {
+			return nil, false, err
+		}
+	}
+
+	// Render template expressions with the variables in scope
+	scope, err := newTemplateScope(projectName, commandName).withVars(yamlConfig.Vars)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if scope, err = scope.withVars(projectConfig.Vars); err != nil {
+		return nil, false, err
+	}
+
+	// Assign the extra arguments to the declared parameters
+	params, err := parseCommandParams(mainCommand, commandIdentifier)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(params) > 0 {
+		for i := range params {
+			if params[i].Default, err = scope.renderTemplate(params[i].Default); err != nil {
+				return nil, false, err
+			}
+		}
+
+		if scope.params, extraArgs, err = resolveCommandParams(params, extraArgs, commandIdentifier); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if projectConfig, err = scope.renderProject(projectConfig); err != nil {
+		return nil, false, err
+	}
+
+	if mainCommand, err = scope.renderTemplateValue(mainCommand); err != nil {
+		return nil, false, err
+	}
+
 	// Resolve project directory path
 	if projectConfig.Dir == "" {
 		projectConfig.Dir = "."
 	}
 
-	projectConfig.Dir = resolveFilePath(projectConfig.Dir, applicationRootPath)
+	projectConfig.Dir = resolveFilePath(projectConfig.Dir, projectConfig.getBaseDir())
+
+	// The commands built here share the loaded configuration and their dynamic environment variables
+	build := &commandBuild{yamlConfig: yamlConfig, envCommands: &envCommandRegistry{}}
+
+	// The project env can reference the variables of the project dotenv files
+	projectDotEnvVars, _, err := loadEnvironmentVariables(parseDotEnvConfiguration(projectConfig.Dotenv, projectConfig.Dir), nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	projectEnv, err := parseEnvMap(
+		projectConfig.Env, "project `"+projectName+"`",
+		envCommandContext{dir: projectConfig.Dir, shell: projectConfig.Shell, envVars: projectDotEnvVars},
+		build.envCommands,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Variables of the project env, and the ones exported by the parameters
+	projectEnvSources := labelEnvSources(projectEnv, "project env")
+	for _, param := range params {
+		paramEnvName := getParamEnvName(param.Name)
+		projectEnv[paramEnvName] = scope.params[param.Name]
+		projectEnvSources[paramEnvName] = "parameter `" + param.Name + "`"
+	}
+
+	registerSecretEnvVars(projectEnv, nil)
 
 	// Build the main command
 	projectCommand, err := buildProjectCommand(
-		mainCommand, projectConfig.Env, projectConfig.Dotenv, []string{}, projectConfig.Watch,
-		projectConfig.Shell, projectConfig.Dir, commandName, projectName, false, isGlobalCommand,
+		mainCommand, projectEnv, projectEnvSources, projectConfig.Dotenv, []string{}, nil, projectConfig.Watch,
+		projectConfig.Shell, projectConfig.Dir, commandName, projectName, false, isGlobalCommand, build,
 	)
 	if err != nil {
 		return nil, false, err
@@ -594,8 +880,8 @@ func getProjectCommand(args []string) (projectCmd *ProjectCommand, projectNotFou
 	// Build pre, post and after commands
 	if projectConfig.Pre != nil {
 		projectCommand.ProjPreCommand, err = buildProjectCommand(
-			projectConfig.Pre, projectConfig.Env, projectConfig.Dotenv, []string{}, nil,
-			projectConfig.Shell, projectConfig.Dir, "pre", projectName, false, isGlobalCommand,
+			projectConfig.Pre, projectEnv, projectEnvSources, projectConfig.Dotenv, []string{}, nil, nil,
+			projectConfig.Shell, projectConfig.Dir, "pre", projectName, false, isGlobalCommand, build,
 		)
 		if err != nil {
 			return nil, false, err
@@ -604,8 +890,8 @@ func getProjectCommand(args []string) (projectCmd *ProjectCommand, projectNotFou
 
 	if projectConfig.Post != nil {
 		projectCommand.ProjPostCommand, err = buildProjectCommand(
-			projectConfig.Post, projectConfig.Env, projectConfig.Dotenv, []string{}, nil,
-			projectConfig.Shell, projectConfig.Dir, "post", projectName, false, isGlobalCommand,
+			projectConfig.Post, projectEnv, projectEnvSources, projectConfig.Dotenv, []string{}, nil, nil,
+			projectConfig.Shell, projectConfig.Dir, "post", projectName, false, isGlobalCommand, build,
 		)
 		if err != nil {
 			return nil, false, err
@@ -614,8 +900,8 @@ func getProjectCommand(args []string) (projectCmd *ProjectCommand, projectNotFou
 
 	if projectConfig.After != nil {
 		projectCommand.ProjAfterCommand, err = buildProjectCommand(
-			projectConfig.After, projectConfig.Env, projectConfig.Dotenv, []string{}, nil,
-			projectConfig.Shell, projectConfig.Dir, "after", projectName, true, isGlobalCommand,
+			projectConfig.After, projectEnv, projectEnvSources, projectConfig.Dotenv, []string{}, nil, nil,
+			projectConfig.Shell, projectConfig.Dir, "after", projectName, true, isGlobalCommand, build,
 		)
 		if err != nil {
 			return nil, false, err
@@ -628,6 +914,28 @@ func getProjectCommand(args []string) (projectCmd *ProjectCommand, projectNotFou
 		projectCommand.CommandList[lastIdx] += " " + strings.Join(utils.AddQuotesToArgsWithSpaces(extraArgs), " ")
 	}
 
+	// Resolve the commands required by `deps`
+	if projectCommand.DependsOn, err = parseCommandDeps(yamlConfig, mainCommand, commandIdentifier, projectName); err != nil {
+		return nil, false, err
+	}
+
+	// Apply the variables inherited and removed by the configuration, the project and the commands
+	projectFilter, isValid := (EnvFilter{}).withSettings(yamlConfig.InheritEnv, yamlConfig.UnsetEnv)
+	if !isValid {
+		return nil, false, fmt.Errorf("The top-level `inherit_env` and `unset_env` fields must be a boolean or a list of variable names")
+	}
+
+	if projectFilter, isValid = projectFilter.withSettings(projectConfig.InheritEnv, projectConfig.UnsetEnv); !isValid {
+		return nil, false, fmt.Errorf("The `inherit_env` and `unset_env` fields of project `%s` must be a boolean or a list of variable names", projectName)
+	}
+
+	projectCommand.applyEnvFilter(projectFilter)
+	for _, projectHook := range []*ProjectCommand{projectCommand.ProjPreCommand, projectCommand.ProjPostCommand, projectCommand.ProjAfterCommand} {
+		if projectHook != nil {
+			projectHook.applyEnvFilter(projectFilter)
+		}
+	}
+
 	projectCommand.Identifier = commandIdentifier
 	return projectCommand, false, nil
 }
@@ -700,8 +1008,10 @@ func findGlobalCommandOrProjectOrProjectCommand(commandArgs []string) (projectNa
 func buildProjectCommand(
 	commandRaw any,
 	commandEnv map[string]string,
+	commandEnvSources map[string]string,
 	commandDotEnv any,
 	envVars []string,
+	envSources map[string]string,
 	commandWatch any,
 	commandShell string,
 	commandPath string,
@@ -709,9 +1019,10 @@ func buildProjectCommand(
 	projName string,
 	isAfterCmd bool,
 	isGlobalCommand bool,
+	build *commandBuild,
 ) (*ProjectCommand, error) {
 	// Load environment variables from dotenv files
-	envVarsFromDotEnv, err := loadEnvironmentVariables(parseDotEnvConfiguration(commandDotEnv, commandPath))
+	envVarsFromDotEnv, dotEnvSources, err := loadEnvironmentVariables(parseDotEnvConfiguration(commandDotEnv, commandPath), envVars)
 	if err != nil {
 		return nil, err
 	}
@@ -719,6 +1030,7 @@ func buildProjectCommand(
 	// Combine environment variables
 	combinedEnvVars := append(envVars, envVarsFromDotEnv...)
 	combinedEnvVars = append(combinedEnvVars, formatEnvironmentMap(commandEnv)...)
+	combinedEnvSources := mergeMaps(mergeMaps(envSources, dotEnvSources), commandEnvSources)
 	effectiveShell := commandShell
 	effectivePath := commandPath
 
@@ -748,9 +1060,9 @@ func buildProjectCommand(
 	switch command := commandRaw.(type) {
 	case map[string]any: // Complex command configuration
 		return buildCommandFromMap(
-			command, commandEnv, commandDotEnv, envVars, combinedEnvVars,
-			commandWatch, commandShell, commandPath, effectivePath,
-			watchPatterns, cmdName, projName, isAfterCmd, isGlobalCommand,
+			command, commandEnv, commandEnvSources, commandDotEnv, envVars, envSources,
+			combinedEnvVars, combinedEnvSources, commandWatch, commandShell, commandPath, effectivePath,
+			watchPatterns, cmdName, projName, isAfterCmd, isGlobalCommand, build,
 		)
 	case any: // Simple command string or list
 		commandList, ok := convertToStringList(command)
@@ -762,8 +1074,10 @@ func buildProjectCommand(
 		}
 
 		return &ProjectCommand{
+			EnvCommands:   build.envCommands,
 			Dir:           effectivePath,
 			EnvVars:       combinedEnvVars,
+			EnvSources:    combinedEnvSources,
 			Shell:         effectiveShell,
 			WatchPatterns: watchPatterns,
 			CommandList:   commandList,
@@ -795,9 +1109,12 @@ func processGlobPatterns(patterns []string, basePath string) []string {
 func buildCommandFromMap(
 	commandMap map[string]any,
 	commandEnv map[string]string,
+	commandEnvSources map[string]string,
 	commandDotEnv any,
 	envVars []string,
+	envSources map[string]string,
 	parentEnvVars []string,
+	parentEnvSources map[string]string,
 	commandWatch any,
 	commandShell string,
 	commandPath string,
@@ -807,17 +1124,30 @@ func buildCommandFromMap(
 	projName string,
 	isAfterCmd bool,
 	isGlobalCommand bool,
+	build *commandBuild,
 ) (*ProjectCommand, error) {
-	projectCmd := &ProjectCommand{}
+	projectCmd := &ProjectCommand{EnvCommands: build.envCommands}
 	var err error
 
+	// Inherit the settings of extended commands
+	if _, hasExtends := commandMap["extends"]; hasExtends {
+		commandIdentifier := cmdName
+		if !isGlobalCommand {
+			commandIdentifier = projName + ":" + cmdName
+		}
+
+		if commandMap, err = resolveCommandExtends(build.yamlConfig, commandMap, commandIdentifier, projName, nil); err != nil {
+			return nil, err
+		}
+	}
+
 	// Special handling for after commands
 	if isAfterCmd {
 		buildAfterCommandHook := func(cmdRaw any, afterCmdName string) (*ProjectCommand, error) {
 			return buildProjectCommand(
-				cmdRaw, commandEnv, commandDotEnv, envVars,
+				cmdRaw, commandEnv, commandEnvSources, commandDotEnv, envVars, envSources,
 				commandWatch, commandShell, commandPath,
-				afterCmdName, projName, false, isGlobalCommand,
+				afterCmdName, projName, false, isGlobalCommand, build,
 			)
 		}
 
@@ -864,8 +1194,8 @@ func buildCommandFromMap(
 	// Resolve command directory
 	commandWorkingDir := resolveFilePath(cmdConfig.Dir, parentPath)
 
-	// Load dotenv variables specific to this command
-	envVarsFromDotEnv, err := loadEnvironmentVariables(parseDotEnvConfiguration(cmdConfig.Dotenv, commandWorkingDir))
+	// Load dotenv variables specific to this command, which can reference the ones of the parent
+	envVarsFromDotEnv, dotEnvSources, err := loadEnvironmentVariables(parseDotEnvConfiguration(cmdConfig.Dotenv, commandWorkingDir), parentEnvVars)
 	if err != nil {
 		return nil, err
 	}
@@ -882,22 +1212,50 @@ func buildCommandFromMap(
 		effectiveWatchPatterns.Exclude = append(effectiveWatchPatterns.Exclude, normalizedExcludes...)
 	}
 
-	// Combine all environment variables
+	// Evaluate the command env, whose commands see the variables set so far
 	combinedEnvVars := append(parentEnvVars, envVarsFromDotEnv...)
-	combinedEnvVars = append(combinedEnvVars, formatEnvironmentMap(cmdConfig.Env)...)
+
+	owner := "command `" + cmdName + "`"
+	if !isGlobalCommand {
+		owner += " in project `" + projName + "`"
+	}
+
+	cmdEnv, err := parseEnvMap(
+		cmdConfig.Env, owner,
+		envCommandContext{dir: commandWorkingDir, shell: effectiveShell, envVars: slices.Clone(combinedEnvVars)},
+		build.envCommands,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Combine all environment variables
+	combinedEnvVars = append(combinedEnvVars, formatEnvironmentMap(cmdEnv)...)
+	cmdEnvSources := labelEnvSources(cmdEnv, "command env")
+	combinedEnvSources := mergeMaps(mergeMaps(parentEnvSources, dotEnvSources), cmdEnvSources)
 
 	// Set up the project command
 	projectCmd.Dir = commandWorkingDir
 	projectCmd.EnvVars = combinedEnvVars
+	projectCmd.EnvSources = combinedEnvSources
+	projectCmd.InheritEnv = cmdConfig.InheritEnv
+	projectCmd.UnsetEnv = cmdConfig.UnsetEnv
 	projectCmd.WatchPatterns = effectiveWatchPatterns
 	projectCmd.Shell = effectiveShell
 	projectCmd.CommandList = cmdConfig.Run
-
-	// Process hooks: after, pre, and post
+	projectCmd.Timeout = cmdConfig.Timeout
+	projectCmd.Condition = cmdConfig.Condition
+	projectCmd.Interactive = cmdConfig.Interactive
+	projectCmd.SharedShell = cmdConfig.SharedShell
+	projectCmd.StepPolicies = cmdConfig.StepPolicies
+	projectCmd.Sources = processGlobPatterns(cmdConfig.Sources, commandWorkingDir)
+	projectCmd.Outputs = processGlobPatterns(cmdConfig.Outputs, commandWorkingDir)
+
+	// Process hooks: after, pre, and post. Their variables already include the dotenv files of the command
 	if !isAfterCmd && cmdConfig.After != nil {
 		projectCmd.AfterCommand, err = buildProjectCommand(
-			cmdConfig.After, cmdConfig.Env, cmdConfig.Dotenv, combinedEnvVars,
-			nil, effectiveShell, commandWorkingDir, "after", projName, true, isGlobalCommand,
+			cmdConfig.After, cmdEnv, cmdEnvSources, nil, combinedEnvVars, combinedEnvSources,
+			nil, effectiveShell, commandWorkingDir, "after", projName, true, isGlobalCommand, build,
 		)
 		if err != nil {
 			return nil, err
@@ -906,8 +1264,8 @@ func buildCommandFromMap(
 
 	if cmdConfig.Pre != nil {
 		projectCmd.PreCommand, err = buildProjectCommand(
-			cmdConfig.Pre, cmdConfig.Env, cmdConfig.Dotenv, combinedEnvVars,
-			nil, effectiveShell, commandWorkingDir, "pre", projName, false, isGlobalCommand,
+			cmdConfig.Pre, cmdEnv, cmdEnvSources, nil, combinedEnvVars, combinedEnvSources,
+			nil, effectiveShell, commandWorkingDir, "pre", projName, false, isGlobalCommand, build,
 		)
 		if err != nil {
 			return nil, err
@@ -916,8 +1274,8 @@ func buildCommandFromMap(
 
 	if cmdConfig.Post != nil {
 		projectCmd.PostCommand, err = buildProjectCommand(
-			cmdConfig.Post, cmdConfig.Env, cmdConfig.Dotenv, combinedEnvVars,
-			nil, effectiveShell, commandWorkingDir, "post", projName, false, isGlobalCommand,
+			cmdConfig.Post, cmdEnv, cmdEnvSources, nil, combinedEnvVars, combinedEnvSources,
+			nil, effectiveShell, commandWorkingDir, "post", projName, false, isGlobalCommand, build,
 		)
 		if err != nil {
 			return nil, err
@@ -928,17 +1286,14 @@ func buildCommandFromMap(
 }
 
 // setupCommandOutputHandling configures stdout/stderr for command execution
-func (cmd *ProjectCommand) setupCommandOutputHandling(processCmd *exec.Cmd, execLogMap map[string]string) (func(), error) {
-	// Create pipes for stdout and stderr
-	stdoutPipe, err := processCmd.StdoutPipe()
-	if err != nil {
-		return nil, fmt.Errorf("Failed to retrieve stdout logs from a command being executed: %v", err)
-	}
-
-	stderrPipe, err := processCmd.StderrPipe()
-	if err != nil {
-		return nil, fmt.Errorf("Failed to retrieve stderr logs from a command being executed: %v", err)
-	}
+func (cmd *ProjectCommand) setupCommandOutputHandling(processCmd *exec.Cmd, execLogMap map[string]string) func() {
+	// Output is copied to pipes closed after the command has finished, so the last lines of
+	// short-lived commands are still read. Processes left holding the output don't block the wait
+	stdoutPipe, stdoutWriter := io.Pipe()
+	stderrPipe, stderrWriter := io.Pipe()
+	processCmd.Stdout = stdoutWriter
+	processCmd.Stderr = stderrWriter
+	processCmd.WaitDelay = outputWaitDelay
 
 	// Set up goroutines to process output
 	var outputWg sync.WaitGroup
@@ -950,27 +1305,19 @@ func (cmd *ProjectCommand) setupCommandOutputHandling(processCmd *exec.Cmd, exec
 		scanner := bufio.NewScanner(stdoutPipe)
 
 		for scanner.Scan() {
-			log := scanner.Text()
+			log := logger.MaskSecrets(scanner.Text())
 			logId := strings.TrimSpace(log)
 
 			if execLog, exists := execLogMap[logId]; exists {
-				if !utils.IsRunningInTestMode() {
-					colorGreen := "\033[0;32m"
-					colorReset := "\033[0m"
-					execLog = colorGreen + execLog + colorReset
-				}
-
-				if cmd.LogPrefix == "" {
-					fmt.Println(execLog)
-				} else {
-					fmt.Println(cmd.GetLogPrefix() + " " + execLog)
-				}
+				cmd.printExecutionLog(execLog)
 			} else if cmd.LogPrefix == "" {
 				fmt.Println(log)
 			} else {
 				fmt.Println(cmd.GetLogPrefix() + " " + log)
 			}
 		}
+
+		io.Copy(io.Discard, stdoutPipe) // Drain lines too long to be scanned
 	}()
 
 	// Process stderr with prefix
@@ -979,62 +1326,215 @@ func (cmd *ProjectCommand) setupCommandOutputHandling(processCmd *exec.Cmd, exec
 		scanner := bufio.NewScanner(stderrPipe)
 
 		for scanner.Scan() {
+			log := logger.MaskSecrets(scanner.Text())
+
 			if cmd.LogPrefix == "" {
-				fmt.Printf("%s\n", scanner.Text())
+				fmt.Println(log)
 			} else {
-				fmt.Printf("%s %s\n", cmd.GetLogPrefix(), scanner.Text())
+				fmt.Println(cmd.GetLogPrefix() + " " + log)
 			}
 		}
+
+		io.Copy(io.Discard, stderrPipe) // Drain lines too long to be scanned
 	}()
 
-	return outputWg.Wait, nil
+	return func() {
+		stdoutWriter.Close()
+		stderrWriter.Close()
+		outputWg.Wait()
+	}
 }
 
-// prepareShellCommands wraps a command with the appropriate shell
+// printsExecutionLogs tells whether the shell prints the execution log of each command of the list
+func (cmd *ProjectCommand) printsExecutionLogs() bool {
+	return cmd.SharedShell && !cmd.Interactive
+}
+
+// getProcessArgs returns the arguments of the process executing a group of commands of the list
+func (cmd *ProjectCommand) getProcessArgs(steps []string) (cmdArgs []string, execLogMap map[string]string, err error) {
+	if cmd.printsExecutionLogs() {
+		return prepareShellCommands(cmd.getShell(), steps)
+	}
+
+	cmdArgs, err = getShellArgs(cmd.getShell(), steps)
+	return cmdArgs, nil, err
+}
+
+// prepareShellCommands wraps a command with the appropriate shell, printing the execution log of each of its commands
 func prepareShellCommands(shell string, cmdArgs []string) (result []string, execLogMap map[string]string, err error) {
-	shellName := strings.TrimSpace(shell)
 	cmdArgsWithLogIds := []string{}
 	execLogMap = make(map[string]string)
 
 	for idx, arg := range cmdArgs {
 		id := logExecId + "_" + strconv.Itoa(idx+1)
 		execLogMap[id] = "Executing `" + arg + "`"
+		cmdArgsWithLogIds = append(cmdArgsWithLogIds, "echo "+id, arg)
+	}
+
+	if result, err = getShellArgs(shell, cmdArgsWithLogIds); err != nil {
+		return nil, nil, err
+	}
+
+	return result, execLogMap, nil
+}
+
+// getShellArgs returns the arguments executing a list of commands in a single shell, stopping on failure
+func getShellArgs(shell string, cmdArgs []string) ([]string, error) {
+	shellName := strings.TrimSpace(shell)
+
+	if runtime.GOOS != "windows" {
+		return []string{shellName, "-c", strings.Join(cmdArgs, " && ")}, nil
+	}
+
+	// PowerShell 5 doesn't support `&&`
+	if shellName == "powershell" {
+		return []string{"powershell", "-Command", strings.Join(cmdArgs, " ; ")}, nil
+	}
+
+	// Arguments given to cmd are split, since the quotes Go adds to them break quoted commands
+	result := []string{shellName, "/C"}
+	for idx, arg := range cmdArgs {
+		if idx > 0 {
+			result = append(result, "&&")
+		}
 
-		if shell == "cmd" && runtime.GOOS == "windows" {
-			if idx > 0 {
-				cmdArgsWithLogIds = append(cmdArgsWithLogIds, "&&")
+		if shellName != "cmd" {
+			result = append(result, arg)
+			continue
+		}
+
+		splitQuotedArgs, err := shellquote.Split(arg)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid format for command `%s`", arg)
+		}
+
+		splitArgs := strings.Split(arg, " ")
+		if len(splitArgs) == len(splitQuotedArgs) {
+			result = append(result, splitArgs...)
+		} else {
+			result = append(result, splitQuotedArgs...)
+		}
+	}
+
+	return result, nil
+}
+
+// Keys a command inherits from the command it extends
+var inheritedCommandKeys = []string{"run", "env", "dotenv", "watch", "shell", "pre", "post", "after"}
+
+// resolveCommandExtends merges the commands a command map extends into it, and resolves its hooks.
+// Explicit keys override inherited ones, except `env` which is merged key by key.
+func resolveCommandExtends(
+	yamlConfig YamlConfig,
+	commandMap map[string]any,
+	commandIdentifier string,
+	projName string,
+	chain []string,
+) (map[string]any, error) {
+	resolved := make(map[string]any, len(commandMap))
+	for key, value := range commandMap {
+		resolved[key] = value
+	}
+
+	chain = append(slices.Clone(chain), commandIdentifier)
+
+	if extendsRaw, hasExtends := commandMap["extends"]; hasExtends {
+		delete(resolved, "extends")
+
+		target, ok := extendsRaw.(string)
+		if !ok || strings.TrimSpace(target) == "" {
+			return nil, fmt.Errorf("Parameter `extends` of command `%s` must be a command name or `project:command`", commandIdentifier)
+		}
+
+		target = strings.TrimSpace(target)
+		baseCommand, baseIdentifier, baseProjName, found := findExtendedCommand(yamlConfig, target, projName, commandIdentifier)
+		if !found {
+			return nil, fmt.Errorf("Command `%s` extended by `%s` was not found", target, commandIdentifier)
+		}
+
+		if utils.SliceContainsValue(chain, baseIdentifier) {
+			return nil, fmt.Errorf("Commands `%s` have a circular `extends` chain", strings.Join(append(chain, baseIdentifier), "` -> `"))
+		}
+
+		// Short commands only define `run`
+		baseMap, isMap := baseCommand.(map[string]any)
+		if !isMap {
+			baseMap = map[string]any{"run": baseCommand}
+		}
+
+		baseMap, err := resolveCommandExtends(yamlConfig, baseMap, baseIdentifier, baseProjName, chain)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range inheritedCommandKeys {
+			baseValue, inherited := baseMap[key]
+			if !inherited {
+				continue
 			}
 
-			cmdArgsWithLogIds = append(cmdArgsWithLogIds, "echo", id, "&&")
-			splitQuotedArgs, err := shellquote.Split(arg)
-			if err != nil {
-				return nil, nil, fmt.Errorf("Invalid format for command `%s`", arg)
+			value, overridden := resolved[key]
+			if !overridden {
+				resolved[key] = baseValue
+				continue
 			}
 
-			splitArgs := strings.Split(arg, " ")
-			if len(splitArgs) == len(splitQuotedArgs) {
-				cmdArgsWithLogIds = append(cmdArgsWithLogIds, splitArgs...)
-			} else {
-				cmdArgsWithLogIds = append(cmdArgsWithLogIds, splitQuotedArgs...)
+			if key == "env" {
+				baseEnv, baseIsMap := baseValue.(map[string]any)
+				env, isMap := value.(map[string]any)
+				if baseIsMap && isMap {
+					mergedEnv := make(map[string]any, len(baseEnv)+len(env))
+					for envKey, envValue := range baseEnv {
+						mergedEnv[envKey] = envValue
+					}
+					for envKey, envValue := range env {
+						mergedEnv[envKey] = envValue
+					}
+					resolved[key] = mergedEnv
+				}
 			}
-		} else {
-			cmdArgsWithLogIds = append(cmdArgsWithLogIds, "echo "+id, arg)
 		}
 	}
 
-	cmdArgs = cmdArgsWithLogIds
+	// Hooks written as command maps may extend other commands too
+	for _, hookKey := range []string{"pre", "post", "after", "success", "failure", "change", "always"} {
+		hookMap, isMap := resolved[hookKey].(map[string]any)
+		if !isMap {
+			continue
+		}
 
-	if runtime.GOOS == "windows" {
-		if shellName == "powershell" {
-			result = []string{"powershell", "-Command", strings.Join(cmdArgs, " ; ")}
-		} else {
-			result = append([]string{shellName, "/C"}, cmdArgs...)
+		hookMap, err := resolveCommandExtends(yamlConfig, hookMap, commandIdentifier+" "+hookKey, projName, chain)
+		if err != nil {
+			return nil, err
 		}
-	} else { // unix
-		result = []string{shellName, "-c", strings.Join(cmdArgs, " && ")}
+
+		resolved[hookKey] = hookMap
 	}
 
-	return result, execLogMap, nil
+	return resolved, nil
+}
+
+// findExtendedCommand returns the command referenced by `extends`, as a sibling command,
+// a global command or a `project:command`, along with its identifier and project
+func findExtendedCommand(yamlConfig YamlConfig, target, projName, commandIdentifier string) (any, string, string, bool) {
+	// A command can extend the global command with the same name
+	if projName != "" && projName+":"+target != commandIdentifier {
+		if command, exists := yamlConfig.Projects[projName].Cmds[target]; exists {
+			return command, projName + ":" + target, projName, true
+		}
+	}
+
+	if command, exists := yamlConfig.Commands[target]; exists {
+		return command, target, "", true
+	}
+
+	if targetProject, targetCommand, found := strings.Cut(target, ":"); found {
+		if command, exists := yamlConfig.Projects[targetProject].Cmds[targetCommand]; exists {
+			return command, target, targetProject, true
+		}
+	}
+
+	return nil, "", "", false
 }
 
 // parseCommandMap extracts command settings from a YAML map structure
@@ -1050,8 +1550,15 @@ func parseCommandMap(cmdData map[string]any, cmdName, projName string, isGlobalC
 		return cmdConfig, fmt.Errorf("Missing required `run` field for command `%s` in project `%s`", cmdName, projName)
 	}
 
+	if err := checkCommandFields(cmdData, cmdName, projName, isGlobalCommand); err != nil {
+		return cmdConfig, err
+	}
+
+	// Parse the failure policy shared by all steps
+	commandPolicy, _ := parseStepPolicy(cmdData, StepPolicy{})
+
 	// Parse the command(s) to run
-	commandList, isValidFormat := convertToStringList(rawCmd)
+	commandList, stepPolicies, isValidFormat := parseRunSteps(rawCmd, commandPolicy)
 	if !isValidFormat {
 		if isGlobalCommand {
 			return cmdConfig, fmt.Errorf("The `run` field for command `%s` must be a command or a list of commands", cmdName)
@@ -1060,6 +1567,7 @@ func parseCommandMap(cmdData map[string]any, cmdName, projName string, isGlobalC
 	}
 
 	cmdConfig.Run = commandList
+	cmdConfig.StepPolicies = stepPolicies
 
 	// Initialize watch patterns
 	cmdConfig.WatchPatterns = struct {
@@ -1095,15 +1603,21 @@ func parseCommandMap(cmdData map[string]any, cmdName, projName string, isGlobalC
 	}
 
 	// Parse environment variables
-	if env, exists := cmdData["env"]; exists {
-		cmdConfig.Env = make(map[string]string)
-		if envData, exists := env.(map[string]any); exists {
-			for keyStr, envVal := range envData {
-				cmdConfig.Env[keyStr] = convertYamlValueToString(envVal)
-			}
+	if env, exists := cmdData["env"].(map[string]any); exists {
+		cmdConfig.Env = env
+	}
+
+	// Parse the variables inherited from the environment of navi, and the removed ones
+	if _, isValid := (EnvFilter{}).withSettings(cmdData["inherit_env"], cmdData["unset_env"]); !isValid {
+		if isGlobalCommand {
+			return cmdConfig, fmt.Errorf("The `inherit_env` and `unset_env` fields for command `%s` must be a boolean or a list of variable names", cmdName)
 		}
+		return cmdConfig, fmt.Errorf("The `inherit_env` and `unset_env` fields of command `%s` in project `%s` must be a boolean or a list of variable names", cmdName, projName)
 	}
 
+	cmdConfig.InheritEnv = cmdData["inherit_env"]
+	cmdConfig.UnsetEnv, _ = convertToStringList(cmdData["unset_env"])
+
 	// Parse hook commands
 	if pre, exists := cmdData["pre"]; exists {
 		cmdConfig.Pre = pre
@@ -1117,9 +1631,360 @@ func parseCommandMap(cmdData map[string]any, cmdName, projName string, isGlobalC
 		cmdConfig.After = after
 	}
 
+	// Parse single shell execution
+	if sharedShell, exists := cmdData["shared_shell"].(bool); exists {
+		cmdConfig.SharedShell = sharedShell
+	}
+
+	// Parse terminal passthrough
+	if interactive, exists := cmdData["interactive"].(bool); exists {
+		cmdConfig.Interactive = interactive
+	}
+
+	// Parse execution condition
+	if condition, exists := cmdData["if"]; exists {
+		cmdConfig.Condition = condition
+	}
+
+	// Parse execution timeout
+	cmdConfig.Timeout, _ = parseTimeout(cmdData["timeout"])
+
+	// Parse cached file patterns
+	cmdConfig.Sources, _ = convertToStringList(cmdData["sources"])
+	cmdConfig.Outputs, _ = convertToStringList(cmdData["outputs"])
+
 	return cmdConfig, nil
 }
 
+// Unknown command fields already reported, so commands loaded several times warn once
+var (
+	reportedUnknownFields = make(map[string]bool)
+	reportedFieldsMutex   sync.Mutex
+)
+
+// checkCommandFields checks the fields of a command against the keys accepted by a detailed command.
+// Unknown fields are ignored with a warning, and reported as errors by `navi validate`
+func checkCommandFields(cmdData map[string]any, cmdName, projName string, isGlobalCommand bool) error {
+	for _, name := range slices.Sorted(maps.Keys(cmdData)) {
+		key, isKnown := findConfigKey(commandKeys, name)
+		if !isKnown {
+			warnUnknownCommandField(name, cmdName, projName, isGlobalCommand)
+			continue
+		}
+
+		expected, isChecked := parsedValueKindDescriptions[key.kind]
+		if !isChecked || cmdData[name] == nil || matchesValueKind(cmdData[name], key.kind) {
+			continue
+		}
+
+		if isGlobalCommand {
+			return fmt.Errorf("The `%s` field for command `%s` must be %s", name, cmdName, expected)
+		}
+		return fmt.Errorf("The `%s` field of command `%s` in project `%s` must be %s", name, cmdName, projName, expected)
+	}
+
+	return nil
+}
+
+// warnUnknownCommandField logs an ignored field of a command, once per field
+func warnUnknownCommandField(name, cmdName, projName string, isGlobalCommand bool) {
+	reportKey := projName + ":" + cmdName + "." + name
+
+	reportedFieldsMutex.Lock()
+	reported := reportedUnknownFields[reportKey]
+	reportedUnknownFields[reportKey] = true
+	reportedFieldsMutex.Unlock()
+
+	if reported {
+		return
+	}
+
+	if isGlobalCommand {
+		logger.Warn("Ignoring unknown field `%s` for command `%s`. Must be one of `%s`", name, cmdName, strings.Join(getConfigKeyNames(commandKeys), "`, `"))
+		return
+	}
+	logger.Warn("Ignoring unknown field `%s` of command `%s` in project `%s`. Must be one of `%s`", name, cmdName, projName, strings.Join(getConfigKeyNames(commandKeys), "`, `"))
+}
+
+// parseRunSteps converts the `run` field to a list of commands, where each step is a command or a map
+// with `run`, `continue_on_error` and `ok_exit_codes`. Policies are nil when every step stops on failure
+func parseRunSteps(rawRun any, commandPolicy StepPolicy) ([]string, []StepPolicy, bool) {
+	rawSteps, isList := rawRun.([]any)
+	if !isList {
+		commandList, ok := convertToStringList(rawRun)
+		if !ok || !commandPolicy.toleratesFailures() {
+			return commandList, nil, ok
+		}
+		return commandList, []StepPolicy{commandPolicy}, true
+	}
+
+	commandList := []string{}
+	stepPolicies := []StepPolicy{}
+	hasTolerantStep := false
+
+	for _, rawStep := range rawSteps {
+		stepPolicy := commandPolicy
+
+		switch step := rawStep.(type) {
+		case string:
+			commandList = append(commandList, step)
+		case map[string]any:
+			command, ok := step["run"].(string)
+			if !ok {
+				return nil, nil, false
+			}
+
+			if stepPolicy, ok = parseStepPolicy(step, commandPolicy); !ok {
+				return nil, nil, false
+			}
+			commandList = append(commandList, command)
+		default:
+			return nil, nil, false
+		}
+
+		stepPolicies = append(stepPolicies, stepPolicy)
+		hasTolerantStep = hasTolerantStep || stepPolicy.toleratesFailures()
+	}
+
+	if !hasTolerantStep {
+		return commandList, nil, true
+	}
+	return commandList, stepPolicies, true
+}
+
+// parseStepPolicy reads `continue_on_error` and `ok_exit_codes`, overriding the values of a parent policy
+func parseStepPolicy(data map[string]any, parentPolicy StepPolicy) (StepPolicy, bool) {
+	policy := parentPolicy
+
+	if continueOnError, exists := data["continue_on_error"].(bool); exists {
+		policy.ContinueOnError = continueOnError
+	}
+
+	if rawCodes, exists := data["ok_exit_codes"]; exists {
+		rawCodeList, isList := rawCodes.([]any)
+		if !isList {
+			rawCodeList = []any{rawCodes}
+		}
+
+		policy.OkExitCodes = []int{}
+		for _, rawCode := range rawCodeList {
+			code, ok := utils.ToInt(rawCode)
+			if !ok {
+				return policy, false
+			}
+			policy.OkExitCodes = append(policy.OkExitCodes, code)
+		}
+	}
+
+	return policy, true
+}
+
+// toleratesFailures checks if the policy accepts any failed exit code
+func (policy StepPolicy) toleratesFailures() bool {
+	return policy.ContinueOnError || slices.ContainsFunc(policy.OkExitCodes, func(code int) bool { return code != 0 })
+}
+
+// toleratesExitCode checks if a step exiting with a failed exit code lets the command continue
+func (policy StepPolicy) toleratesExitCode(exitCode int) bool {
+	return policy.ContinueOnError || slices.Contains(policy.OkExitCodes, exitCode)
+}
+
+// parseTimeout converts a duration like `5m` or a number of seconds to a positive duration
+func parseTimeout(value any) (time.Duration, bool) {
+	if seconds, ok := utils.ToFloat64(value); ok {
+		return time.Duration(seconds * float64(time.Second)), seconds > 0
+	}
+
+	durationText, ok := value.(string)
+	if !ok {
+		return 0, false
+	}
+
+	duration, err := time.ParseDuration(strings.TrimSpace(durationText))
+	return duration, err == nil && duration > 0
+}
+
+// formatTimeout formats a duration without trailing zero units, like `5m` instead of `5m0s`
+func formatTimeout(duration time.Duration) string {
+	formatted := duration.String()
+	if strings.HasSuffix(formatted, "m0s") {
+		formatted = strings.TrimSuffix(formatted, "0s")
+	}
+	if strings.HasSuffix(formatted, "h0m") {
+		formatted = strings.TrimSuffix(formatted, "0m")
+	}
+	return formatted
+}
+
+// parseCommandParams extracts the parameters declared by a detailed command
+func parseCommandParams(commandRaw any, commandIdentifier string) ([]CommandParam, error) {
+	commandMap, ok := commandRaw.(map[string]any)
+	if !ok || commandMap["params"] == nil {
+		return nil, nil
+	}
+
+	paramsList, ok := commandMap["params"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("Parameter `params` of command `%s` must be a list of parameters", commandIdentifier)
+	}
+
+	params := []CommandParam{}
+	for _, paramRaw := range paramsList {
+		paramMap, ok := paramRaw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("Parameters of command `%s` must be maps with a `name` key", commandIdentifier)
+		}
+
+		name, ok := paramMap["name"].(string)
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("Parameters of command `%s` must be maps with a `name` key", commandIdentifier)
+		}
+
+		param := CommandParam{Name: strings.TrimSpace(name)}
+
+		if description, ok := paramMap["description"].(string); ok {
+			param.Description = description
+		}
+
+		if defaultValue, exists := paramMap["default"]; exists && defaultValue != nil {
+			param.Default = convertYamlValueToString(defaultValue)
+			param.HasDefault = true
+		}
+
+		if required, ok := paramMap["required"].(bool); ok {
+			param.Required = required
+		}
+
+		if allowed, exists := paramMap["allowed"]; exists {
+			allowedList, ok := allowed.([]any)
+			if !ok {
+				return nil, fmt.Errorf("Parameter `allowed` of `%s` in command `%s` must be a list of values", param.Name, commandIdentifier)
+			}
+
+			for _, value := range allowedList {
+				param.Allowed = append(param.Allowed, convertYamlValueToString(value))
+			}
+		}
+
+		params = append(params, param)
+	}
+
+	return params, nil
+}
+
+// resolveCommandParams assigns the `--name=value`, `--name value` and positional
+// arguments to the declared parameters, returning the arguments after `--`
+func resolveCommandParams(params []CommandParam, args []string, commandIdentifier string) (map[string]string, []string, error) {
+	values := make(map[string]string)
+	var positionalArgs, remainingArgs []string
+
+	findParam := func(name string) (CommandParam, error) {
+		for _, param := range params {
+			if param.Name == name {
+				return param, nil
+			}
+		}
+
+		names := make([]string, len(params))
+		for i, param := range params {
+			names[i] = param.Name
+		}
+
+		return CommandParam{}, fmt.Errorf(
+			"Unknown parameter `%s` for command `%s`. Declared parameters: `%s`",
+			name, commandIdentifier, strings.Join(names, "`, `"),
+		)
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			remainingArgs = args[i+1:]
+			break
+		}
+
+		if !strings.HasPrefix(arg, "--") {
+			positionalArgs = append(positionalArgs, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		param, err := findParam(name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("Missing value for parameter `%s` of command `%s`", param.Name, commandIdentifier)
+			}
+			i++
+			value = args[i]
+		}
+
+		values[param.Name] = value
+	}
+
+	// Positional arguments fill the parameters not given by name, in declaration order
+	for _, param := range params {
+		if len(positionalArgs) == 0 {
+			break
+		}
+
+		if _, given := values[param.Name]; !given {
+			values[param.Name] = positionalArgs[0]
+			positionalArgs = positionalArgs[1:]
+		}
+	}
+
+	if len(positionalArgs) > 0 {
+		return nil, nil, fmt.Errorf(
+			"Too many arguments for command `%s`: `%s`. Use `--` to pass extra arguments to the command",
+			commandIdentifier, strings.Join(positionalArgs, " "),
+		)
+	}
+
+	for _, param := range params {
+		value, given := values[param.Name]
+
+		if !given {
+			if !param.HasDefault {
+				if param.Required {
+					return nil, nil, fmt.Errorf("Missing required parameter `%s` for command `%s`", param.Name, commandIdentifier)
+				}
+
+				values[param.Name] = ""
+				continue
+			}
+
+			if len(param.Allowed) > 0 && !utils.SliceContainsValue(param.Allowed, param.Default) {
+				return nil, nil, fmt.Errorf(
+					"Invalid default value `%s` for parameter `%s` of command `%s`. Must be one of `%s`",
+					param.Default, param.Name, commandIdentifier, strings.Join(param.Allowed, "`, `"),
+				)
+			}
+
+			values[param.Name] = param.Default
+			continue
+		}
+
+		if len(param.Allowed) > 0 && !utils.SliceContainsValue(param.Allowed, value) {
+			return nil, nil, fmt.Errorf(
+				"Invalid value `%s` for parameter `%s` of command `%s`. Must be one of `%s`",
+				value, param.Name, commandIdentifier, strings.Join(param.Allowed, "`, `"),
+			)
+		}
+	}
+
+	return values, remainingArgs, nil
+}
+
+// getParamEnvName returns the environment variable a parameter is exported as
+func getParamEnvName(paramName string) string {
+	return strings.ToUpper(strings.ReplaceAll(paramName, "-", "_"))
+}
+
 // parseWatchPatterns parses file watch patterns from configuration
 func parseWatchPatterns(watchData any, projName, cmdName string, isGlobalCommand bool) (includePatterns []string, excludePatterns []string, err error) {
 	if watchData == nil {
@@ -0,0 +1,188 @@
+package navi
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/go-navi/navi/internal/logger"
+)
+
+// Maximum execution time of the probe command of a `cmd` condition
+const conditionProbeTimeout = 10 * time.Second
+
+// skipIfConditionNotMet checks the `if` condition of a command and logs when it is skipped
+func (cmd *ProjectCommand) skipIfConditionNotMet(ctx Ctx, condition any) (bool, error) {
+	if condition == nil {
+		return false, nil
+	}
+
+	met, reason, err := cmd.evaluateCondition(ctx, condition)
+	if err != nil {
+		return false, fmt.Errorf("Invalid `if` condition: %v", err)
+	}
+
+	if !met {
+		logger.InfoWithPrefix(cmd.GetLogPrefix(), "Skipping execution: %s", reason)
+	}
+
+	return !met, nil
+}
+
+// evaluateCondition checks an `if` condition in the directory and environment of the command.
+// When it isn't met, the returned reason describes the first check that failed
+func (cmd *ProjectCommand) evaluateCondition(ctx Ctx, condition any) (bool, string, error) {
+	switch value := condition.(type) {
+	case []any: // All conditions of a list must be met
+		for _, item := range value {
+			met, reason, err := cmd.evaluateCondition(ctx, item)
+			if err != nil || !met {
+				return met, reason, err
+			}
+		}
+		return true, "", nil
+
+	case map[string]any: // All checks of a map must be met
+		for key := range value {
+			if _, known := findConfigKey(conditionKeys, key); !known {
+				return false, "", fmt.Errorf("unknown condition `%s`. Must be one of `%s`", key, strings.Join(getConfigKeyNames(conditionKeys), "`, `"))
+			}
+		}
+
+		for _, key := range getConfigKeyNames(conditionKeys) {
+			if checkValue, exists := value[key]; exists {
+				met, reason, err := cmd.evaluateConditionCheck(ctx, key, checkValue)
+				if err != nil || !met {
+					return met, reason, err
+				}
+			}
+		}
+		return true, "", nil
+	}
+
+	return false, "", fmt.Errorf("must be a map of conditions or a list of conditions")
+}
+
+// evaluateConditionCheck checks a single key of an `if` condition
+func (cmd *ProjectCommand) evaluateConditionCheck(ctx Ctx, key string, checkValue any) (bool, string, error) {
+	switch key {
+	case "all", "any":
+		conditions, ok := checkValue.([]any)
+		if !ok {
+			return false, "", fmt.Errorf("`%s` must be a list of conditions", key)
+		}
+
+		if key == "all" {
+			return cmd.evaluateCondition(ctx, conditions)
+		}
+
+		for _, item := range conditions {
+			met, _, err := cmd.evaluateCondition(ctx, item)
+			if err != nil || met {
+				return met, "", err
+			}
+		}
+		return false, "none of the `any` conditions is met", nil
+
+	case "not":
+		met, _, err := cmd.evaluateCondition(ctx, checkValue)
+		if err != nil {
+			return false, "", err
+		}
+		return !met, "the `not` condition is met", nil
+
+	case "cmd":
+		probe, ok := checkValue.(string)
+		if !ok || strings.TrimSpace(probe) == "" {
+			return false, "", fmt.Errorf("`cmd` must be a command")
+		}
+
+		// Probes are stopped on shutdown, and when they hang
+		probeCtx, cancel := context.WithTimeout(ctx.Ctx, conditionProbeTimeout)
+		defer cancel()
+
+		shellArgs, err := getShellArgs(cmd.getShell(), []string{probe})
+		if err != nil {
+			return false, "", err
+		}
+
+		probeCmd := exec.CommandContext(probeCtx, shellArgs[0], shellArgs[1:]...)
+		probeCmd.Dir = cmd.Dir
+		probeCmd.Env = cmd.EnvFilter.apply(os.Environ(), cmd.EnvVars)
+		probeCmd.WaitDelay = time.Second // Don't wait for processes left holding the output
+
+		err = probeCmd.Run()
+		if probeCtx.Err() == context.DeadlineExceeded {
+			return false, fmt.Sprintf("`%s` did not finish within %s", probe, formatTimeout(conditionProbeTimeout)), nil
+		}
+
+		if err != nil {
+			return false, fmt.Sprintf("`%s` failed", probe), nil
+		}
+		return true, "", nil
+	}
+
+	values, ok := convertToStringList(checkValue)
+	if !ok {
+		return false, "", fmt.Errorf("`%s` must be a string or a list of strings", key)
+	}
+
+	switch key {
+	case "env":
+		for _, envCheck := range values {
+			name, expected, hasValue := strings.Cut(envCheck, "=")
+			actual, isSet := cmd.lookupEnv(strings.TrimSpace(name))
+
+			if hasValue && actual != expected {
+				return false, fmt.Sprintf("environment variable `%s` is not `%s`", strings.TrimSpace(name), expected), nil
+			}
+
+			if !hasValue && (!isSet || actual == "") {
+				return false, fmt.Sprintf("environment variable `%s` is not set", strings.TrimSpace(name)), nil
+			}
+		}
+
+	case "exists":
+		for _, pattern := range values {
+			matches, err := doublestar.FilepathGlob(filepath.ToSlash(resolveFilePath(pattern, cmd.Dir)))
+			if err != nil || len(matches) == 0 {
+				return false, fmt.Sprintf("`%s` does not exist", pattern), nil
+			}
+		}
+
+	case "os":
+		for _, osName := range values {
+			if strings.EqualFold(strings.TrimSpace(osName), runtime.GOOS) {
+				return true, "", nil
+			}
+		}
+		return false, fmt.Sprintf("the operating system is `%s`, not `%s`", runtime.GOOS, strings.Join(values, "` or `")), nil
+	}
+
+	return true, "", nil
+}
+
+// lookupEnv returns the value of an environment variable as seen by the command
+func (cmd *ProjectCommand) lookupEnv(name string) (string, bool) {
+	if cmd.EnvFilter.isUnset(name) {
+		return "", false
+	}
+
+	for i := len(cmd.EnvVars) - 1; i >= 0; i-- {
+		if key, value, found := strings.Cut(cmd.EnvVars[i], "="); found && key == name {
+			return value, true
+		}
+	}
+
+	if cmd.EnvFilter.Restricted && !matchesEnvNamePattern(cmd.EnvFilter.Inherit, name) {
+		return "", false
+	}
+
+	return os.LookupEnv(name)
+}
@@ -6,32 +6,98 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/goccy/go-yaml"
-	"github.com/joho/godotenv"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+
+	"github.com/go-navi/navi/internal/logger"
 )
 
 // Global configuration variables
 var (
-	applicationRootPath string // Root directory of the application
-	configurationPath   string // Path to the configuration file
-	cachedYamlFile      string // Cached yaml file string
+	applicationRootPath string                    // Root directory of the application
+	configurationPath   string                    // Path to the configuration file
+	overlayPaths        []string                  // Files deep-merged onto the configuration, in precedence order
+	activeProfile       string                    // Profile applied to the configuration
+	forceExecution      bool                      // Whether commands are executed even when their sources are up to date
+	cachedYamlFiles     = make(map[string]string) // Cached yaml file strings by path
+	cachedYamlMutex     sync.Mutex                // Guards cachedYamlFiles, read by concurrent runner entries
+	secretEnvPatterns   []string                  // Name patterns of the variables masked in the output
 )
 
-// getYamlConfiguration loads and parses YAML config into the specified type
+// Name of the personal overlay file merged onto the configuration file next to it
+const localOverlayFileName = "navi.local.yml"
+
+// getYamlConfiguration loads and parses YAML config, merging any included and overlay files
 func getYamlConfiguration(replaceEnvVars bool) (YamlConfig, yaml.CommentMap, error) {
-	var configResult YamlConfig
+	configResult, commentsMap, err := loadYamlConfigurationTree(configurationPath, replaceEnvVars, map[string]bool{})
+	if err != nil {
+		return configResult, commentsMap, err
+	}
 
-	content := string(cachedYamlFile)
-	if content == "" {
-		fileData, err := os.ReadFile(configurationPath)
+	for _, overlayPath := range overlayPaths {
+		overlayConfig, overlayComments, err := loadYamlConfigurationTree(overlayPath, replaceEnvVars, map[string]bool{})
 		if err != nil {
-			return configResult, nil, err
+			return configResult, commentsMap, fmt.Errorf("%v (in overlay `%s`)", err, getDisplayPath(overlayPath))
+		}
+
+		if err := configResult.applyOverlay(overlayConfig, overlayPath); err != nil {
+			return configResult, commentsMap, err
+		}
+
+		for key, comments := range overlayComments {
+			if _, exists := commentsMap[key]; !exists {
+				commentsMap[key] = comments
+			}
+		}
+	}
+
+	if activeProfile != "" {
+		if err := configResult.applyProfile(activeProfile); err != nil {
+			return configResult, commentsMap, err
 		}
+	}
+
+	if err := configResult.applyTemplates(); err != nil {
+		return configResult, commentsMap, err
+	}
+
+	return configResult, commentsMap, nil
+}
+
+// readYamlFile returns the content of a configuration file, reading it only once
+func readYamlFile(path string) (string, error) {
+	cachedYamlMutex.Lock()
+	defer cachedYamlMutex.Unlock()
+
+	if content, exists := cachedYamlFiles[path]; exists {
+		return content, nil
+	}
+
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	cachedYamlFiles[path] = string(fileData)
+	return cachedYamlFiles[path], nil
+}
 
-		content = string(fileData)
-		cachedYamlFile = string(fileData)
+// loadYamlFile parses a single configuration file into the config structure
+func loadYamlFile(path string, replaceEnvVars bool) (YamlConfig, yaml.CommentMap, error) {
+	var configResult YamlConfig
+
+	content, err := readYamlFile(path)
+	if err != nil {
+		return configResult, nil, err
 	}
 
 	if replaceEnvVars {
@@ -43,12 +109,740 @@ func getYamlConfiguration(replaceEnvVars bool) (YamlConfig, yaml.CommentMap, err
 
 	commentsMap := yaml.CommentMap{}
 
-	return configResult, commentsMap, yaml.UnmarshalWithOptions(
+	err = yaml.UnmarshalWithOptions(
 		[]byte(content),
 		&configResult,
 		yaml.Strict(),
 		yaml.CommentToMap(commentsMap),
 	)
+	if err != nil {
+		return configResult, commentsMap, err
+	}
+
+	// Keep track of where each definition comes from
+	baseDir := strings.TrimSuffix(filepath.ToSlash(filepath.Dir(path)), "/")
+	configResult.definitionFiles = make(map[string]string)
+	configResult.commandDirs = make(map[string]string)
+
+	for name := range configResult.Vars {
+		configResult.definitionFiles["vars."+name] = path
+	}
+
+	for name := range configResult.Commands {
+		configResult.definitionFiles["commands."+name] = path
+		configResult.commandDirs[name] = baseDir
+	}
+
+	for name, project := range configResult.Projects {
+		configResult.definitionFiles["projects."+name] = path
+		project.baseDir = baseDir
+		configResult.Projects[name] = project
+	}
+
+	for name := range configResult.Runners {
+		configResult.definitionFiles["runners."+name] = path
+	}
+
+	for name := range configResult.Profiles {
+		configResult.definitionFiles["profiles."+name] = path
+	}
+
+	for name := range configResult.Templates {
+		configResult.definitionFiles["templates."+name] = path
+	}
+
+	return configResult, commentsMap, nil
+}
+
+// loadYamlConfigurationTree loads a configuration file and merges its `include` files into it
+func loadYamlConfigurationTree(path string, replaceEnvVars bool, visitedFiles map[string]bool) (YamlConfig, yaml.CommentMap, error) {
+	visitedFiles[path] = true
+
+	configResult, commentsMap, err := loadYamlFile(path, replaceEnvVars)
+	if err != nil {
+		return configResult, commentsMap, err
+	}
+
+	includedFiles, err := resolveIncludedFiles(configResult.Include, filepath.Dir(path))
+	if err != nil {
+		return configResult, commentsMap, fmt.Errorf("%v (included from `%s`)", err, getDisplayPath(path))
+	}
+
+	for _, includedFile := range includedFiles {
+		if visitedFiles[includedFile] {
+			continue // Already merged
+		}
+
+		includedConfig, includedComments, err := loadYamlConfigurationTree(includedFile, replaceEnvVars, visitedFiles)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return configResult, commentsMap, fmt.Errorf("Included configuration file not found. Path: %s", includedFile)
+			}
+			return configResult, commentsMap, err
+		}
+
+		if err := configResult.merge(includedConfig); err != nil {
+			return configResult, commentsMap, err
+		}
+
+		for key, comments := range includedComments {
+			if _, exists := commentsMap[key]; !exists {
+				commentsMap[key] = comments
+			}
+		}
+	}
+
+	return configResult, commentsMap, nil
+}
+
+// resolveIncludedFiles expands the `include` entries of a configuration file into file paths
+func resolveIncludedFiles(include any, baseDirPath string) ([]string, error) {
+	if include == nil {
+		return nil, nil
+	}
+
+	patterns, ok := convertToStringList(include)
+	if !ok {
+		return nil, fmt.Errorf("Parameter `include` must be a file path or a list of file paths")
+	}
+
+	var files []string
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+
+		resolvedPattern := resolveFilePath(pattern, baseDirPath)
+
+		// Plain paths are used as they are, so a missing file is reported
+		if !strings.ContainsAny(pattern, "*?[{") {
+			files = append(files, resolvedPattern)
+			continue
+		}
+
+		matches, err := doublestar.FilepathGlob(resolvedPattern, doublestar.WithFilesOnly())
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pattern `%s` in parameter `include`: %v", pattern, err)
+		}
+
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	return files, nil
+}
+
+// merge adds the definitions of another configuration, failing on name collisions
+func (config *YamlConfig) merge(other YamlConfig) error {
+	if config.Commands == nil {
+		config.Commands = make(map[string]any)
+	}
+
+	if config.Projects == nil {
+		config.Projects = make(map[string]ProjectConfig)
+	}
+
+	if config.Runners == nil {
+		config.Runners = make(map[string]any)
+	}
+
+	if config.Vars == nil {
+		config.Vars = make(map[string]any)
+	}
+
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]ProfileConfig)
+	}
+
+	if config.Templates == nil {
+		config.Templates = make(map[string]ProjectConfig)
+	}
+
+	for name, value := range other.Vars {
+		if _, exists := config.Vars[name]; exists {
+			return config.getCollisionError(other, "Variable", "vars", name)
+		}
+
+		config.Vars[name] = value
+	}
+
+	for name, command := range other.Commands {
+		if _, exists := config.Commands[name]; exists {
+			return config.getCollisionError(other, "Command", "commands", name)
+		}
+
+		config.Commands[name] = command
+		config.commandDirs[name] = other.commandDirs[name]
+	}
+
+	for name, project := range other.Projects {
+		if _, exists := config.Projects[name]; exists {
+			return config.getCollisionError(other, "Project", "projects", name)
+		}
+
+		config.Projects[name] = project
+	}
+
+	for name, runner := range other.Runners {
+		if _, exists := config.Runners[name]; exists {
+			return config.getCollisionError(other, "Runner", "runners", name)
+		}
+
+		config.Runners[name] = runner
+	}
+
+	for name, profile := range other.Profiles {
+		if _, exists := config.Profiles[name]; exists {
+			return config.getCollisionError(other, "Profile", "profiles", name)
+		}
+
+		config.Profiles[name] = profile
+	}
+
+	for name, template := range other.Templates {
+		if _, exists := config.Templates[name]; exists {
+			return config.getCollisionError(other, "Template", "templates", name)
+		}
+
+		config.Templates[name] = template
+	}
+
+	config.Secrets = append(config.Secrets, other.Secrets...)
+
+	// Settings of the including file take precedence, while unset variables add up
+	if config.InheritEnv == nil {
+		config.InheritEnv = other.InheritEnv
+	}
+	config.UnsetEnv = appendStringLists(config.UnsetEnv, other.UnsetEnv)
+
+	for key, path := range other.definitionFiles {
+		config.definitionFiles[key] = path
+	}
+
+	return nil
+}
+
+// applyTemplates adds the settings of their template to the projects using one.
+// Settings of the project take precedence, `env` and `vars` are merged key by key
+// and commands are added or replaced one by one.
+func (config *YamlConfig) applyTemplates() error {
+	for projectName, project := range config.Projects {
+		if project.Template == "" {
+			continue
+		}
+
+		template, exists := config.Templates[project.Template]
+		if !exists {
+			return fmt.Errorf("Template `%s` used by project `%s` is not defined", project.Template, projectName)
+		}
+
+		if project.Shell == "" {
+			project.Shell = template.Shell
+		}
+
+		if project.Watch == nil {
+			project.Watch = template.Watch
+		}
+
+		if project.Dotenv == nil {
+			project.Dotenv = template.Dotenv
+		}
+
+		if project.Pre == nil {
+			project.Pre = template.Pre
+		}
+
+		if project.Post == nil {
+			project.Post = template.Post
+		}
+
+		if project.After == nil {
+			project.After = template.After
+		}
+
+		if project.InheritEnv == nil {
+			project.InheritEnv = template.InheritEnv
+		}
+
+		project.UnsetEnv = appendStringLists(template.UnsetEnv, project.UnsetEnv)
+		project.Env = mergeMaps(template.Env, project.Env)
+		project.Vars = mergeMaps(template.Vars, project.Vars)
+		project.Cmds = mergeMaps(template.Cmds, project.Cmds)
+
+		config.Projects[projectName] = project
+	}
+
+	return nil
+}
+
+// appendStringLists joins two values written as a string or a list of strings, keeping nil when both are unset
+func appendStringLists(base, other any) any {
+	if base == nil {
+		return other
+	}
+
+	if other == nil {
+		return base
+	}
+
+	baseList, _ := convertToStringList(base)
+	otherList, _ := convertToStringList(other)
+
+	result := []any{}
+	for _, item := range slices.Concat(baseList, otherList) {
+		result = append(result, item)
+	}
+	return result
+}
+
+// mergeMaps returns a map with the entries of both maps, the ones of the second map taking precedence
+func mergeMaps[T any](base, other map[string]T) map[string]T {
+	if len(base) == 0 {
+		return other
+	}
+
+	merged := make(map[string]T, len(base)+len(other))
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range other {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// configOverrides holds settings deep-merged onto the configuration by a profile or an overlay file
+type configOverrides struct {
+	ProfileConfig
+	commandDirs map[string]string // Directory of the file setting each command
+	projectDirs map[string]string // Directory of the file setting each project
+	source      string            // Origin of the overrides, used in errors
+}
+
+// valueMerger deep-merges override values, recording the paths of the values it sets
+type valueMerger struct {
+	file     string            // File the overrides come from
+	setPaths map[string]string // Path of each value set => file setting it (nil = not recorded)
+}
+
+// applyProfile deep-merges the overrides of a profile onto the configuration
+func (config *YamlConfig) applyProfile(name string) error {
+	profile, exists := config.Profiles[name]
+	if !exists {
+		var profileNames []string
+		for profileName := range config.Profiles {
+			profileNames = append(profileNames, profileName)
+		}
+
+		if len(profileNames) == 0 {
+			return fmt.Errorf("Profile `%s` not found. No profiles are defined in the configuration", name)
+		}
+
+		sort.Strings(profileNames)
+		return fmt.Errorf("Profile `%s` not found. Available profiles: `%s`", name, strings.Join(profileNames, "`, `"))
+	}
+
+	// Paths set by the profile are relative to the file defining it
+	profileDir := strings.TrimSuffix(filepath.ToSlash(filepath.Dir(config.definitionFiles["profiles."+name])), "/")
+
+	overrides := configOverrides{
+		ProfileConfig: profile,
+		commandDirs:   make(map[string]string),
+		projectDirs:   make(map[string]string),
+		source:        fmt.Sprintf("profile `%s`", name),
+	}
+
+	for commandName := range profile.Commands {
+		overrides.commandDirs[commandName] = profileDir
+	}
+
+	for projectName := range profile.Projects {
+		overrides.projectDirs[projectName] = profileDir
+	}
+
+	return config.applyOverrides(overrides, valueMerger{})
+}
+
+// applyOverlay deep-merges a configuration loaded from an overlay file onto the configuration
+func (config *YamlConfig) applyOverlay(overlay YamlConfig, path string) error {
+	overrides := configOverrides{
+		ProfileConfig: ProfileConfig{
+			Vars:     overlay.Vars,
+			Commands: overlay.Commands,
+			Projects: make(map[string]map[string]any),
+			Runners:  overlay.Runners,
+		},
+		commandDirs: overlay.commandDirs,
+		projectDirs: make(map[string]string),
+		source:      "`" + getDisplayPath(path) + "`",
+	}
+
+	for projectName, project := range overlay.Projects {
+		overrides.Projects[projectName] = project.getRawProject()
+		overrides.projectDirs[projectName] = project.getBaseDir()
+	}
+
+	if config.overlayValues == nil {
+		config.overlayValues = make(map[string]string)
+	}
+
+	if err := config.applyOverrides(overrides, valueMerger{file: path, setPaths: config.overlayValues}); err != nil {
+		return err
+	}
+
+	config.Secrets = append(config.Secrets, overlay.Secrets...)
+
+	if overlay.InheritEnv != nil {
+		config.InheritEnv = overlay.InheritEnv
+	}
+	config.UnsetEnv = appendStringLists(config.UnsetEnv, overlay.UnsetEnv)
+
+	// Templates of the overlay are merged like projects
+	if config.Templates == nil {
+		config.Templates = make(map[string]ProjectConfig)
+	}
+
+	for templateName, template := range overlay.Templates {
+		mergedTemplate, err := valueMerger{}.mergeProject(config.Templates[templateName], template.getRawProject(), "templates."+templateName)
+		if err != nil {
+			return fmt.Errorf("Invalid settings for template `%s` in `%s`: %v", templateName, getDisplayPath(path), err)
+		}
+
+		config.Templates[templateName] = mergedTemplate
+	}
+
+	// Profiles of the overlay are merged with the ones of the same name
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]ProfileConfig)
+	}
+
+	for profileName, profile := range overlay.Profiles {
+		if existingProfile, exists := config.Profiles[profileName]; exists {
+			profile = mergeProfiles(existingProfile, profile)
+		} else {
+			config.definitionFiles["profiles."+profileName] = overlay.definitionFiles["profiles."+profileName]
+		}
+
+		config.Profiles[profileName] = profile
+	}
+
+	// Definitions only found in the overlay point to it
+	for key, definitionPath := range overlay.definitionFiles {
+		if _, exists := config.definitionFiles[key]; !exists {
+			config.definitionFiles[key] = definitionPath
+		}
+	}
+
+	return nil
+}
+
+// applyOverrides deep-merges vars, commands and projects, and replaces runners with the same name
+func (config *YamlConfig) applyOverrides(overrides configOverrides, merger valueMerger) error {
+	if config.Vars == nil {
+		config.Vars = make(map[string]any)
+	}
+
+	for varName, value := range overrides.Vars {
+		config.Vars[varName] = merger.mergeValues(config.Vars[varName], value, "vars."+varName)
+	}
+
+	if config.Commands == nil {
+		config.Commands = make(map[string]any)
+	}
+
+	for commandName, override := range overrides.Commands {
+		existingCommand, exists := config.Commands[commandName]
+		config.Commands[commandName] = merger.mergeCommand(existingCommand, override, "commands."+commandName)
+
+		if !exists || overridesKey(override, "dir") {
+			config.commandDirs[commandName] = overrides.commandDirs[commandName]
+		}
+	}
+
+	if config.Projects == nil {
+		config.Projects = make(map[string]ProjectConfig)
+	}
+
+	for projectName, override := range overrides.Projects {
+		existingProject, exists := config.Projects[projectName]
+
+		project, err := merger.mergeProject(existingProject, override, "projects."+projectName)
+		if err != nil {
+			return fmt.Errorf("Invalid settings for project `%s` in %s: %v", projectName, overrides.source, err)
+		}
+
+		project.baseDir = existingProject.baseDir
+		if !exists || overridesKey(override, "dir") {
+			project.baseDir = overrides.projectDirs[projectName]
+		}
+
+		config.Projects[projectName] = project
+	}
+
+	if config.Runners == nil {
+		config.Runners = make(map[string]any)
+	}
+
+	for runnerKey, runner := range overrides.Runners {
+		replaceRunner(config.Runners, runnerKey, runner)
+		merger.record("runners." + runnerKey)
+	}
+
+	return nil
+}
+
+// mergeProject deep-merges project settings given as a map onto a project
+func (merger valueMerger) mergeProject(project ProjectConfig, override map[string]any, path string) (ProjectConfig, error) {
+	mergedProject := project.getRawProject()
+	for key, value := range override {
+		if key == "cmds" {
+			mergedProject[key] = merger.mergeCommandMap(mergedProject[key], value, path+".cmds")
+		} else {
+			mergedProject[key] = merger.mergeValues(mergedProject[key], value, path+"."+key)
+		}
+	}
+
+	return convertToProjectConfig(mergedProject)
+}
+
+// mergeProfiles deep-merges the overrides of two profiles with the same name
+func mergeProfiles(profile, other ProfileConfig) ProfileConfig {
+	merger := valueMerger{}
+	merged := ProfileConfig{
+		Vars:     make(map[string]any),
+		Commands: make(map[string]any),
+		Projects: make(map[string]map[string]any),
+		Runners:  make(map[string]any),
+	}
+
+	for name, value := range profile.Vars {
+		merged.Vars[name] = value
+	}
+
+	for name, value := range other.Vars {
+		merged.Vars[name] = value
+	}
+
+	for name, command := range profile.Commands {
+		merged.Commands[name] = command
+	}
+
+	for name, override := range other.Commands {
+		merged.Commands[name] = merger.mergeCommand(merged.Commands[name], override, "")
+	}
+
+	for name, project := range profile.Projects {
+		merged.Projects[name] = project
+	}
+
+	for name, override := range other.Projects {
+		mergedProject := make(map[string]any)
+		for key, value := range merged.Projects[name] {
+			mergedProject[key] = value
+		}
+
+		for key, value := range override {
+			if key == "cmds" {
+				mergedProject[key] = merger.mergeCommandMap(mergedProject[key], value, "")
+			} else {
+				mergedProject[key] = merger.mergeValues(mergedProject[key], value, "")
+			}
+		}
+
+		merged.Projects[name] = mergedProject
+	}
+
+	for runnerKey, runner := range profile.Runners {
+		merged.Runners[runnerKey] = runner
+	}
+
+	for runnerKey, runner := range other.Runners {
+		replaceRunner(merged.Runners, runnerKey, runner)
+	}
+
+	return merged
+}
+
+// replaceRunner sets a runner, removing the runner with the same name and different flags
+func replaceRunner(runners map[string]any, runnerKey string, runner any) {
+	for existingKey := range runners {
+		if stripFlags(existingKey) == stripFlags(runnerKey) {
+			delete(runners, existingKey)
+		}
+	}
+
+	runners[runnerKey] = runner
+}
+
+// record stores the path of a value set by the overrides
+func (merger valueMerger) record(path string) {
+	if merger.setPaths != nil {
+		merger.setPaths[path] = merger.file
+	}
+}
+
+// mergeCommandMap deep-merges a map of command overrides onto a map of commands
+func (merger valueMerger) mergeCommandMap(commands, overrides any, path string) any {
+	commandsMap, ok := commands.(map[string]any)
+	overridesMap, isMap := overrides.(map[string]any)
+	if !ok || !isMap {
+		return merger.mergeValues(commands, overrides, path)
+	}
+
+	merged := make(map[string]any, len(commandsMap)+len(overridesMap))
+	for commandName, command := range commandsMap {
+		merged[commandName] = command
+	}
+
+	for commandName, override := range overridesMap {
+		merged[commandName] = merger.mergeCommand(commandsMap[commandName], override, joinConfigPath(path, commandName))
+	}
+
+	return merged
+}
+
+// mergeCommand deep-merges a command override, turning short commands into `run` settings
+func (merger valueMerger) mergeCommand(command, override any, path string) any {
+	if _, isMap := override.(map[string]any); isMap && command != nil {
+		if _, isDetailed := command.(map[string]any); !isDetailed {
+			command = map[string]any{"run": command}
+		}
+	}
+
+	return merger.mergeValues(command, override, path)
+}
+
+// mergeValues merges maps key by key, any other override value replaces the original one
+func (merger valueMerger) mergeValues(original, override any, path string) any {
+	originalMap, originalIsMap := convertToAnyMap(original)
+	overrideMap, overrideIsMap := convertToAnyMap(override)
+	if !originalIsMap || !overrideIsMap {
+		merger.record(path)
+		return override
+	}
+
+	merged := make(map[string]any, len(originalMap)+len(overrideMap))
+	for key, value := range originalMap {
+		merged[key] = value
+	}
+
+	for key, value := range overrideMap {
+		merged[key] = merger.mergeValues(originalMap[key], value, joinConfigPath(path, key))
+	}
+
+	return merged
+}
+
+// convertToAnyMap returns a map of strings or a generic map as a generic map
+func convertToAnyMap(value any) (map[string]any, bool) {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		return typedValue, true
+	case map[string]string:
+		converted := make(map[string]any, len(typedValue))
+		for key, item := range typedValue {
+			converted[key] = item
+		}
+		return converted, true
+	}
+	return nil, false
+}
+
+// overridesKey checks if an override is a map setting a key
+func overridesKey(override any, key string) bool {
+	overrideMap, isMap := override.(map[string]any)
+	if !isMap {
+		return false
+	}
+
+	_, exists := overrideMap[key]
+	return exists
+}
+
+// convertToProjectConfig decodes project settings given as a map
+func convertToProjectConfig(rawProject map[string]any) (ProjectConfig, error) {
+	var project ProjectConfig
+
+	content, err := yaml.Marshal(rawProject)
+	if err != nil {
+		return project, err
+	}
+
+	err = yaml.UnmarshalWithOptions(content, &project, yaml.Strict())
+	return project, err
+}
+
+// getCollisionError builds an error pointing at both definitions of a duplicated name
+func (config *YamlConfig) getCollisionError(other YamlConfig, kind, section, name string) error {
+	existingFile := config.definitionFiles[section+"."+name]
+	duplicateFile := other.definitionFiles[section+"."+name]
+
+	return fmt.Errorf(
+		"%s `%s` is defined in both `%s` and `%s`",
+		kind,
+		name,
+		getDefinitionLocation(existingFile, section, name),
+		getDefinitionLocation(duplicateFile, section, name),
+	)
+}
+
+// getDefinitionLocation returns `file:line` for a top-level definition
+func getDefinitionLocation(path, section, name string) string {
+	location := getDisplayPath(path)
+
+	content, err := readYamlFile(path)
+	if err != nil {
+		return location
+	}
+
+	if line := findDefinitionLine(content, section, name); line > 0 {
+		location += ":" + strconv.Itoa(line)
+	}
+
+	return location
+}
+
+// findDefinitionLine returns the line of the `section.name` key in YAML content (0 if not found)
+func findDefinitionLine(content, section, name string) int {
+	file, err := parser.ParseBytes([]byte(content), 0)
+	if err != nil || len(file.Docs) == 0 {
+		return 0
+	}
+
+	for _, sectionNode := range getMappingValues(file.Docs[0].Body) {
+		if sectionNode.Key.GetToken().Value != section {
+			continue
+		}
+
+		for _, entry := range getMappingValues(sectionNode.Value) {
+			if entry.Key.GetToken().Value == name {
+				return entry.Key.GetToken().Position.Line
+			}
+		}
+	}
+
+	return 0
+}
+
+// getMappingValues returns the key-value entries of a YAML mapping node
+func getMappingValues(node ast.Node) []*ast.MappingValueNode {
+	switch value := node.(type) {
+	case *ast.MappingNode:
+		return value.Values
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{value}
+	}
+	return nil
+}
+
+// getDisplayPath returns a path relative to the root directory when possible
+func getDisplayPath(path string) string {
+	if relPath, err := filepath.Rel(applicationRootPath, path); err == nil && !strings.HasPrefix(relPath, "..") {
+		return filepath.ToSlash(relPath)
+	}
+	return path
 }
 
 // resolveFilePath handles path resolution with support for ROOT placeholders
@@ -88,70 +882,125 @@ func parseDotEnvConfiguration(dotEnvConfig any, baseDirPath string) DotEnvConfig
 	}
 
 	var envFileConfig DotEnvConfig
-	var entries []string
+	var entries []any
 
-	// Convert the different possible input types to string slice
+	// Convert the different possible input types to a list of entries
 	switch value := dotEnvConfig.(type) {
-	case string:
-		entries = []string{value}
+	case string, map[string]any:
+		entries = []any{value}
 	case []any:
+		entries = value
+	case []string:
 		for _, item := range value {
-			if str, ok := item.(string); ok {
-				entries = append(entries, str)
-			}
+			entries = append(entries, item)
 		}
-	case []string:
-		entries = value
 	}
 
-	// Process each entry
+	// Process each entry, written as `path | KEYS` or as a map with `path`, `format`, `separator` and `secret`
 	for _, entry := range entries {
-		entry = strings.TrimSpace(entry)
-		if entry == "" {
-			continue
-		}
+		switch value := entry.(type) {
+		case string:
+			if file, ok := parseDotEnvEntry(value, baseDirPath); ok {
+				envFileConfig.Files = append(envFileConfig.Files, file)
+			}
 
-		// Parse path and optional keys
-		parts := strings.Split(entry, "|")
-		path := resolveFilePath(parts[0], baseDirPath)
+		case map[string]any:
+			path, _ := value["path"].(string)
+			file, ok := parseDotEnvEntry(path, baseDirPath)
+			if !ok {
+				continue
+			}
 
-		var keys []string
-		if len(parts) > 1 {
-			for _, key := range strings.Split(parts[1], ",") {
-				keys = append(keys, strings.TrimSpace(key))
+			file.Format, _ = value["format"].(string)
+			file.Separator, _ = value["separator"].(string)
+			if secret, _ := value["secret"].(bool); secret {
+				file.Secret = true
 			}
-		}
 
-		envFileConfig.Files = append(envFileConfig.Files, DotEnvFile{
-			Path: path,
-			Keys: keys,
-		})
+			envFileConfig.Files = append(envFileConfig.Files, file)
+		}
 	}
 
 	envFileConfig.Valid = len(envFileConfig.Files) > 0
 	return envFileConfig
 }
 
-// loadEnvironmentVariables loads and processes vars from .env files
-func loadEnvironmentVariables(config DotEnvConfig) ([]string, error) {
+// parseDotEnvEntry parses an environment file path, optionally followed by `| KEY1, KEY2` and `secret`
+func parseDotEnvEntry(entry string, baseDirPath string) (DotEnvFile, bool) {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		return DotEnvFile{}, false
+	}
+
+	// Parse path and optional keys
+	parts := strings.Split(entry, "|")
+	path := resolveFilePath(parts[0], baseDirPath)
+
+	var keys []string
+	secret := false
+	if len(parts) > 1 {
+		keysPart := strings.TrimSpace(parts[1])
+
+		// A trailing `secret` marks the loaded values as secrets, as in `.env | * secret`.
+		// Alone, as in `.env | secret`, it marks all the values of the file
+		if fields := strings.Fields(keysPart); len(fields) > 0 && fields[len(fields)-1] == "secret" {
+			secret = true
+			keysPart = strings.TrimSpace(strings.TrimSuffix(keysPart, "secret"))
+		}
+
+		if keysPart != "*" && keysPart != "" {
+			for _, key := range strings.Split(keysPart, ",") {
+				keys = append(keys, strings.TrimSpace(key))
+			}
+		}
+	}
+
+	return DotEnvFile{
+		Path:   path,
+		Keys:   keys,
+		Secret: secret,
+	}, true
+}
+
+// loadEnvironmentVariables loads and processes vars from environment files, returning the file each one comes from.
+// References are expanded against the same file, the previous files and the variables set so far
+func loadEnvironmentVariables(config DotEnvConfig, parentEnvVars []string) ([]string, map[string]string, error) {
 	if !config.Valid {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	envVarsMap := make(map[string]string)
+	envSources := make(map[string]string)
+	secretKeys := make(map[string]bool)
 
 	// Process each env file
 	for _, file := range config.Files {
-		fileEnv, err := godotenv.Read(file.Path)
+		fileEnv, err := readEnvFile(file)
+		if err == nil {
+			fileEnv, err = expandEnvLayer(fileEnv, nil, append(slices.Clone(parentEnvVars), formatEnvironmentMap(envVarsMap)...))
+		}
+
 		if err != nil {
-			return nil, fmt.Errorf("Failed to load environment file `%s`: %v", file.Path, err)
+			return nil, nil, fmt.Errorf("Failed to load environment file `%s`: %v", file.Path, err)
+		}
+
+		source := "dotenv " + getDisplayPath(file.Path)
+
+		// Variables of secret files are masked, even when overridden by a later file
+		if file.Secret {
+			for k := range fileEnv {
+				if len(file.Keys) == 0 || slices.Contains(file.Keys, k) {
+					secretKeys[k] = true
+				}
+			}
 		}
 
 		// Handle specified keys or all keys
 		if len(file.Keys) == 0 {
 			// Load all variables
 			for k, v := range fileEnv {
-				envVarsMap[k] = replaceEnvironmentVariables(v, false)
+				envVarsMap[k] = v
+				envSources[k] = source
 			}
 			continue
 		}
@@ -159,14 +1008,110 @@ func loadEnvironmentVariables(config DotEnvConfig) ([]string, error) {
 		// Load only specified keys
 		for _, key := range file.Keys {
 			if val, exists := fileEnv[key]; exists {
-				envVarsMap[key] = replaceEnvironmentVariables(val, false)
+				envVarsMap[key] = val
+				envSources[key] = source
 			} else {
-				return nil, fmt.Errorf("Environment variable `%s` not found in file `%s`", key, file.Path)
+				return nil, nil, fmt.Errorf("Environment variable `%s` not found in file `%s`", key, file.Path)
 			}
 		}
 	}
 
-	return formatEnvironmentMap(envVarsMap), nil
+	registerSecretEnvVars(envVarsMap, secretKeys)
+	return formatEnvironmentMap(envVarsMap), envSources, nil
+}
+
+// setSecretEnvPatterns sets the name patterns of the variables masked in the output,
+// registering the matching variables of the environment of navi
+func setSecretEnvPatterns(patterns []string) {
+	secretEnvPatterns = patterns
+
+	envMap := make(map[string]string)
+	for _, envVar := range os.Environ() {
+		if key, value, found := strings.Cut(envVar, "="); found {
+			envMap[key] = value
+		}
+	}
+
+	registerSecretEnvVars(envMap, nil)
+}
+
+// registerSecretEnvVars masks in the output the values of the given keys,
+// and of the variables matching a secret name pattern
+func registerSecretEnvVars(envMap map[string]string, secretKeys map[string]bool) {
+	for key, value := range envMap {
+		if secretKeys[key] || isSecretEnvName(key) {
+			logger.RegisterSecret(key, value, secretKeys[key])
+		}
+	}
+}
+
+// isSecretEnvName checks if a variable name matches a secret name pattern, like `*_TOKEN`
+func isSecretEnvName(name string) bool {
+	return matchesEnvNamePattern(secretEnvPatterns, name)
+}
+
+// parseEnvMap converts the values of an `env` map to strings. A value can also be a map with
+// `value` or `sh`, a command whose output is the value, and `secret`, to mask it in the output.
+// References of the values are expanded against the map and the variables of the context.
+// Values of `sh` are placeholders until a command using them starts
+func parseEnvMap(envData map[string]any, owner string, cmdContext envCommandContext, envCommands *envCommandRegistry) (map[string]string, error) {
+	envValues := make(map[string]string, len(envData))
+	envPlaceholders := make(map[string]string)
+	secretKeys := make(map[string]bool)
+
+	for key, rawValue := range envData {
+		settings, isMap := rawValue.(map[string]any)
+		if !isMap {
+			envValues[key] = convertYamlValueToString(rawValue)
+			continue
+		}
+
+		value, hasValue := settings["value"]
+		script, hasScript := settings["sh"]
+
+		switch {
+		case hasValue && hasScript:
+			return nil, fmt.Errorf("Environment variable `%s` of %s can't set both `value` and `sh`", key, owner)
+
+		case hasScript:
+			scriptText, ok := script.(string)
+			if !ok || strings.TrimSpace(scriptText) == "" {
+				return nil, fmt.Errorf("The `sh` field of environment variable `%s` of %s must be a command", key, owner)
+			}
+
+			timeout := defaultEnvCommandTimeout
+			if rawTimeout, exists := settings["timeout"]; exists {
+				if timeout, ok = parseTimeout(rawTimeout); !ok {
+					return nil, fmt.Errorf("The `timeout` field of environment variable `%s` of %s must be a duration like `30s` or a number of seconds", key, owner)
+				}
+			}
+
+			// Evaluated when a command using it starts, so `--dry-run` and `navi inspect` don't execute it
+			secret, _ := settings["secret"].(bool)
+			envPlaceholders[key] = envCommands.register(envCommand{
+				name: key, owner: owner, script: scriptText, timeout: timeout, secret: secret, context: cmdContext,
+			})
+			continue
+
+		case hasValue:
+			envValues[key] = convertYamlValueToString(value)
+
+		default:
+			return nil, fmt.Errorf("Missing `value` or `sh` for environment variable `%s` of %s", key, owner)
+		}
+
+		if secret, ok := settings["secret"].(bool); ok && secret {
+			secretKeys[key] = true
+		}
+	}
+
+	envMap, err := expandEnvLayer(envValues, envPlaceholders, cmdContext.envVars)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to expand environment variables of %s: %v", owner, err)
+	}
+
+	registerSecretEnvVars(envMap, secretKeys)
+	return envMap, nil
 }
 
 // formatEnvironmentMap converts a map to KEY=VALUE string slice
@@ -182,6 +1127,15 @@ func formatEnvironmentMap(envMap map[string]string) []string {
 	return result
 }
 
+// labelEnvSources returns the same source for each variable of an environment map
+func labelEnvSources(envMap map[string]string, source string) map[string]string {
+	envSources := make(map[string]string, len(envMap))
+	for key := range envMap {
+		envSources[key] = source
+	}
+	return envSources
+}
+
 // convertYamlValueToString handles conversion of YAML values to string representation
 func convertYamlValueToString(val any) string {
 	switch v := val.(type) {
@@ -212,6 +1166,18 @@ func convertYamlValueToString(val any) string {
 
 // replaceEnvironmentVariables expands environment variables in strings
 func replaceEnvironmentVariables(input string, escapeChars bool) string {
+	return expandReferences(input, func(key string) (string, bool) {
+		val, exists := os.LookupEnv(key)
+		if exists && escapeChars {
+			val = strings.ReplaceAll(val, "\\", "\\\\")
+			val = strings.ReplaceAll(val, "\"", "\\\"")
+		}
+		return val, exists
+	})
+}
+
+// expandReferences replaces the variable references found by lookup, keeping the unknown ones as written
+func expandReferences(input string, lookup func(key string) (string, bool)) string {
 	// Handle escaped dollar signs
 	input = strings.ReplaceAll(input, "\\\\$", "\uF002")
 	input = strings.ReplaceAll(input, "\\$", "\uF001")
@@ -219,11 +1185,7 @@ func replaceEnvironmentVariables(input string, escapeChars bool) string {
 
 	// Replace environment variables
 	result := os.Expand(input, func(key string) string {
-		if val, exists := os.LookupEnv(key); exists {
-			if escapeChars {
-				val = strings.ReplaceAll(val, "\\", "\\\\")
-				val = strings.ReplaceAll(val, "\"", "\\\"")
-			}
+		if val, exists := lookup(key); exists {
 			return val
 		}
 		return "${" + key + "}"
@@ -249,6 +1211,197 @@ func replaceRootPathPlaceholders(input string) string {
 	return strings.ReplaceAll(input, "__ROOT__", stdPath)
 }
 
+// Matches `{{ .vars.name }}`, `{{ .params.name }}` and built-in `{{ .project }}`, `{{ .command }}` and `{{ .root }}` expressions
+var templateExpressionRegex = regexp.MustCompile(`\{\{\s*\.(vars\.([A-Za-z0-9_-]+)|params\.([A-Za-z0-9_-]+)|project|command|root)\s*\}\}`)
+
+// templateScope holds the values available to template expressions
+type templateScope struct {
+	vars     map[string]string // User-defined variables
+	params   map[string]string // Command parameter values
+	builtins map[string]string // Built-in values
+}
+
+// newTemplateScope creates a scope with the built-in values of a command
+func newTemplateScope(projectName, commandName string) templateScope {
+	return templateScope{
+		vars:   make(map[string]string),
+		params: make(map[string]string),
+		builtins: map[string]string{
+			"project": projectName,
+			"command": commandName,
+			"root":    applicationRootPath,
+		},
+	}
+}
+
+// withVars returns a copy of the scope overridden by additional variables,
+// which can reference each other and the variables already in scope
+func (scope templateScope) withVars(vars any) (templateScope, error) {
+	if vars == nil {
+		return scope, nil
+	}
+
+	varsMap, ok := vars.(map[string]any)
+	if !ok {
+		return scope, fmt.Errorf("Parameter `vars` must be a map of variables")
+	}
+
+	newScope := templateScope{vars: make(map[string]string), params: scope.params, builtins: scope.builtins}
+	for name, value := range scope.vars {
+		newScope.vars[name] = value
+	}
+
+	// Resolve each variable once, following references to the other new variables
+	resolved := make(map[string]bool)
+	resolving := make(map[string]bool)
+
+	var resolveVar func(name string) error
+	resolveVar = func(name string) error {
+		if resolved[name] {
+			return nil
+		}
+
+		if resolving[name] {
+			return fmt.Errorf("Variable `%s` has a circular reference", name)
+		}
+
+		resolving[name] = true
+		rawValue := convertYamlValueToString(varsMap[name])
+
+		// A variable referencing its own name gets the overridden value
+		for _, match := range templateExpressionRegex.FindAllStringSubmatch(rawValue, -1) {
+			if _, isNewVar := varsMap[match[2]]; isNewVar && match[2] != name {
+				if err := resolveVar(match[2]); err != nil {
+					return err
+				}
+			}
+		}
+
+		value, err := newScope.renderTemplate(rawValue)
+		if err != nil {
+			return err
+		}
+
+		newScope.vars[name] = value
+		resolved[name] = true
+		return nil
+	}
+
+	for name := range varsMap {
+		if err := resolveVar(name); err != nil {
+			return scope, err
+		}
+	}
+
+	return newScope, nil
+}
+
+// renderTemplate replaces template expressions in a string
+func (scope templateScope) renderTemplate(input string) (string, error) {
+	if !strings.Contains(input, "{{") {
+		return input, nil
+	}
+
+	var renderErr error
+	result := templateExpressionRegex.ReplaceAllStringFunc(input, func(expression string) string {
+		match := templateExpressionRegex.FindStringSubmatch(expression)
+
+		if varName := match[2]; varName != "" {
+			value, exists := scope.vars[varName]
+			if !exists && renderErr == nil {
+				renderErr = fmt.Errorf("Variable `%s` used in `%s` is not defined", varName, input)
+			}
+			return value
+		}
+
+		if paramName := match[3]; paramName != "" {
+			value, exists := scope.params[paramName]
+			if !exists && renderErr == nil {
+				renderErr = fmt.Errorf("Parameter `%s` used in `%s` is not declared", paramName, input)
+			}
+			return value
+		}
+
+		return scope.builtins[match[1]]
+	})
+
+	return result, renderErr
+}
+
+// renderTemplateValue replaces template expressions in all strings of a YAML value,
+// applying the `vars` defined in nested maps to their own values
+func (scope templateScope) renderTemplateValue(value any) (any, error) {
+	switch typedValue := value.(type) {
+	case string:
+		return scope.renderTemplate(typedValue)
+
+	case []any:
+		result := make([]any, len(typedValue))
+		for i, item := range typedValue {
+			renderedItem, err := scope.renderTemplateValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = renderedItem
+		}
+		return result, nil
+
+	case map[string]any:
+		mapScope, err := scope.withVars(typedValue["vars"])
+		if err != nil {
+			return nil, err
+		}
+
+		result := make(map[string]any, len(typedValue))
+		for key, item := range typedValue {
+			if key == "vars" {
+				result[key] = item
+				continue
+			}
+
+			renderedItem, err := mapScope.renderTemplateValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = renderedItem
+		}
+		return result, nil
+	}
+
+	return value, nil
+}
+
+// renderProject replaces template expressions in the settings of a project
+func (scope templateScope) renderProject(proj ProjectConfig) (ProjectConfig, error) {
+	var err error
+
+	if proj.Dir, err = scope.renderTemplate(proj.Dir); err != nil {
+		return proj, err
+	}
+
+	if proj.Shell, err = scope.renderTemplate(proj.Shell); err != nil {
+		return proj, err
+	}
+
+	for _, field := range []*any{&proj.Pre, &proj.Post, &proj.After, &proj.Dotenv, &proj.Watch} {
+		if *field, err = scope.renderTemplateValue(*field); err != nil {
+			return proj, err
+		}
+	}
+
+	if len(proj.Env) > 0 {
+		renderedEnv := make(map[string]any, len(proj.Env))
+		for key, value := range proj.Env {
+			if renderedEnv[key], err = scope.renderTemplateValue(value); err != nil {
+				return proj, err
+			}
+		}
+		proj.Env = renderedEnv
+	}
+
+	return proj, nil
+}
+
 // createContext creates a cancellable context wrapper
 func createContext(parent context.Context) Ctx {
 	ctx, cancel := context.WithCancel(parent)
@@ -267,8 +1420,17 @@ func (ctx *Ctx) Done() <-chan struct{} {
 var (
 	ErrProcessTerminated = errors.New("Process terminated by 'interrupt' or 'termination' signal")
 	ErrWatchModeRestart  = errors.New("Process terminated by watch mode restart")
+	ErrCommandTimeout    = errors.New("The command timed out")
 )
 
+// getBaseDir returns the directory relative paths of the project are resolved against
+func (proj *ProjectConfig) getBaseDir() string {
+	if proj.baseDir == "" {
+		return applicationRootPath
+	}
+	return proj.baseDir
+}
+
 // getRawProject returns a shallow copy of the project configuration as a map
 func (proj *ProjectConfig) getRawProject() map[string]any {
 	shallowCopy := make(map[string]any)
@@ -309,5 +1471,21 @@ func (proj *ProjectConfig) getRawProject() map[string]any {
 		shallowCopy["shell"] = proj.Shell
 	}
 
+	if len(proj.Vars) > 0 {
+		shallowCopy["vars"] = proj.Vars
+	}
+
+	if proj.Template != "" {
+		shallowCopy["template"] = proj.Template
+	}
+
+	if proj.InheritEnv != nil {
+		shallowCopy["inherit_env"] = proj.InheritEnv
+	}
+
+	if proj.UnsetEnv != nil {
+		shallowCopy["unset_env"] = proj.UnsetEnv
+	}
+
 	return shallowCopy
 }
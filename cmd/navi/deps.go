@@ -0,0 +1,163 @@
+package navi
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-navi/navi/internal/logger"
+	"github.com/go-navi/navi/internal/utils"
+	"github.com/go-navi/navi/internal/watcher"
+)
+
+// dependencyExecution tracks a command executed once per invocation
+type dependencyExecution struct {
+	done         chan struct{} // Closed when the command has finished
+	err          error         // Result of the command
+	byDependency bool          // Whether the execution was started by a dependency
+}
+
+// Dependencies and runner entries already started in this invocation, by command identifier
+var dependencyExecutions = make(map[string]*dependencyExecution)
+var dependencyExecutionsMutex sync.Mutex
+
+// parseCommandDeps returns the identifiers of the commands listed in `deps`, resolved
+// as a sibling command, a global command or a `project:command`
+func parseCommandDeps(yamlConfig YamlConfig, commandRaw any, commandIdentifier, projName string) ([]string, error) {
+	commandMap, ok := commandRaw.(map[string]any)
+	if !ok || commandMap["deps"] == nil {
+		return nil, nil
+	}
+
+	depNames, ok := convertToStringList(commandMap["deps"])
+	if !ok {
+		return nil, fmt.Errorf("Parameter `deps` of command `%s` must be a command name or a list of command names", commandIdentifier)
+	}
+
+	var dependsOn []string
+	for _, depName := range depNames {
+		depName = strings.TrimSpace(depName)
+
+		_, depIdentifier, _, found := findExtendedCommand(yamlConfig, depName, projName, commandIdentifier)
+		if !found {
+			return nil, fmt.Errorf("Command `%s` required by `%s` was not found", depName, commandIdentifier)
+		}
+
+		if !utils.SliceContainsValue(dependsOn, depIdentifier) {
+			dependsOn = append(dependsOn, depIdentifier)
+		}
+	}
+
+	return dependsOn, nil
+}
+
+// resolveCommandDependencies builds the commands a command depends on, recursively, and detects cycles
+func resolveCommandDependencies(projectCmd *ProjectCommand, chain []string) error {
+	chain = append(slices.Clone(chain), projectCmd.Identifier)
+	projectCmd.Dependencies = nil
+
+	for _, depIdentifier := range projectCmd.DependsOn {
+		if utils.SliceContainsValue(chain, depIdentifier) {
+			return fmt.Errorf("Commands `%s` have a circular `deps` chain", strings.Join(append(chain, depIdentifier), "` -> `"))
+		}
+
+		depCmd, _, err := getProjectCommand([]string{depIdentifier})
+		if err != nil {
+			return err
+		}
+
+		if err := resolveCommandDependencies(depCmd, chain); err != nil {
+			return err
+		}
+
+		// Dependencies run once, without watch mode
+		depCmd.WatchPatterns = watcher.FilePatterns{}
+		projectCmd.Dependencies = append(projectCmd.Dependencies, depCmd)
+	}
+
+	return nil
+}
+
+// runCommandDependencies executes the dependencies of a command in parallel and waits for them
+func runCommandDependencies(contextCmd Ctx, projectCmd *ProjectCommand) error {
+	results := make(chan error, len(projectCmd.Dependencies))
+
+	for _, depCmd := range projectCmd.Dependencies {
+		go func(depCmd *ProjectCommand) {
+			results <- runDependencyOnce(contextCmd, depCmd)
+		}(depCmd)
+	}
+
+	var firstErr error
+	for range projectCmd.Dependencies {
+		if err := <-results; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// runDependencyOnce executes a dependency, or waits for the result of its first execution
+func runDependencyOnce(contextCmd Ctx, depCmd *ProjectCommand) error {
+	err, _ := runOnce(depCmd.Identifier, true, func() error {
+		// Nested dependencies are executed first, then the dependency itself
+		setupCommandLogPrefix(depCmd, depCmd.Identifier, "")
+		return runCommandWithAfterHandling(contextCmd, depCmd, false)
+	})
+
+	if err == nil || errors.Is(err, ErrProcessTerminated) {
+		return err
+	}
+
+	return &commandFailureError{fmt.Sprintf("Dependency `%s` failed: %v", depCmd.Identifier, err), err}
+}
+
+// runRunnerEntryOnce executes a runner entry, sharing its execution with the dependencies
+// of the same command, so a command that is both an entry and a dependency runs once
+func runRunnerEntryOnce(contextCmd Ctx, projectCmd *ProjectCommand) error {
+	if !projectCmd.SharedExecution {
+		return executeCommandWithAfterHandling(contextCmd, projectCmd)
+	}
+
+	err, reused := runOnce(projectCmd.Identifier, false, func() error {
+		return executeCommandWithAfterHandling(contextCmd, projectCmd)
+	})
+
+	if reused {
+		logger.InfoWithPrefix(projectCmd.GetLogPrefix(), "Already executed as a dependency")
+		if err != nil && !errors.Is(err, ErrProcessTerminated) {
+			logger.ErrorWithPrefix(projectCmd.GetLogPrefix(), "%v", err)
+		}
+	}
+
+	return err
+}
+
+// runOnce executes a command once per identifier, or waits for the result of its first execution.
+// Runner entries only reuse executions started by dependencies, so repeated entries still run
+func runOnce(identifier string, byDependency bool, run func() error) (err error, reused bool) {
+	dependencyExecutionsMutex.Lock()
+	execution, started := dependencyExecutions[identifier]
+	if started && (byDependency || execution.byDependency) {
+		dependencyExecutionsMutex.Unlock()
+		<-execution.done
+		return execution.err, true
+	}
+
+	if started {
+		dependencyExecutionsMutex.Unlock()
+		return run(), false
+	}
+
+	execution = &dependencyExecution{done: make(chan struct{}), byDependency: byDependency}
+	dependencyExecutions[identifier] = execution
+	dependencyExecutionsMutex.Unlock()
+
+	defer close(execution.done)
+	execution.err = run()
+
+	return execution.err, false
+}
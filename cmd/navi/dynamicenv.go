@@ -0,0 +1,244 @@
+package navi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-navi/navi/internal/logger"
+)
+
+// Maximum execution time of the command of a dynamic environment variable, unless set with `timeout`
+const defaultEnvCommandTimeout = 10 * time.Second
+
+// Marks the placeholders of dynamic environment variables, replaced by their values when commands start
+const envCommandMark = "\uF004"
+
+// Placeholder of a dynamic environment variable, holding the index of its command
+var envCommandPlaceholderRegex = regexp.MustCompile(envCommandMark + `(\d+)` + envCommandMark)
+
+// Outputs of the commands of dynamic environment variables, evaluated once per invocation
+var (
+	envCommandResults = make(map[string]*envCommandResult)
+	envCommandMutex   sync.Mutex
+)
+
+// envCommandResult is the output of a command of a dynamic environment variable, evaluated once
+// by the first command using it while the others wait for it
+type envCommandResult struct {
+	once   sync.Once
+	output string
+	err    error
+}
+
+// envCommand is the command of a dynamic environment variable, executed when a command using it starts
+type envCommand struct {
+	name    string            // Name of the variable
+	owner   string            // Project or command defining the variable
+	script  string            // Command whose output is the value
+	timeout time.Duration     // Maximum execution time
+	secret  bool              // Whether the value is masked in the output
+	context envCommandContext // Where the command is executed
+}
+
+// envCommandContext is where the commands of dynamic environment variables are executed
+type envCommandContext struct {
+	dir     string   // Working directory
+	shell   string   // Shell executing the command (empty = default shell)
+	envVars []string // Variables set on top of the environment of navi
+}
+
+// envCommandRegistry holds the commands of the dynamic environment variables of a command and its hooks
+type envCommandRegistry struct {
+	commands []envCommand
+}
+
+// register stores the command of a dynamic environment variable and returns
+// the placeholder standing for its value until a command using it starts
+func (registry *envCommandRegistry) register(command envCommand) string {
+	registry.commands = append(registry.commands, command)
+	return envCommandMark + strconv.Itoa(len(registry.commands)-1) + envCommandMark
+}
+
+// get returns the command of a dynamic environment variable from its placeholder
+func (registry *envCommandRegistry) get(placeholder string) (envCommand, bool) {
+	index, err := strconv.Atoi(strings.Trim(placeholder, envCommandMark))
+	if registry == nil || err != nil || index >= len(registry.commands) {
+		return envCommand{}, false
+	}
+	return registry.commands[index], true
+}
+
+// resolve replaces the placeholders of dynamic environment variables with the outputs of
+// their commands, executed with the environment a process gets through the given filter
+func (registry *envCommandRegistry) resolve(envVars []string, filter EnvFilter) ([]string, error) {
+	resolved := make([]string, len(envVars))
+	for i, envVar := range envVars {
+		var resolveErr error
+		resolved[i] = envCommandPlaceholderRegex.ReplaceAllStringFunc(envVar, func(placeholder string) string {
+			command, exists := registry.get(placeholder)
+			if resolveErr != nil || !exists {
+				return placeholder
+			}
+
+			output, err := registry.evaluate(command, filter)
+			resolveErr = err
+			return output
+		})
+
+		if resolveErr != nil {
+			return nil, resolveErr
+		}
+
+		// Values built from dynamic ones are masked like the others
+		if name, value, _ := strings.Cut(resolved[i], "="); resolved[i] != envVar && isSecretEnvName(name) {
+			logger.RegisterSecret(name, value, false)
+		}
+	}
+	return resolved, nil
+}
+
+// describe replaces the placeholders of dynamic environment variables with their
+// commands, shown by `--dry-run` and `navi inspect` without executing them
+func (registry *envCommandRegistry) describe(value string) string {
+	if placeholder := envCommandPlaceholderRegex.FindString(value); placeholder != "" && placeholder == value {
+		if command, exists := registry.get(placeholder); exists {
+			return "sh: " + command.script
+		}
+	}
+
+	return envCommandPlaceholderRegex.ReplaceAllStringFunc(value, func(placeholder string) string {
+		if command, exists := registry.get(placeholder); exists {
+			return "$(" + command.script + ")"
+		}
+		return placeholder
+	})
+}
+
+// evaluate returns the output of the command of a dynamic environment variable, without its
+// trailing line break. Commands are executed once for each directory, shell and environment,
+// and the output is reused by the commands and runner entries sharing them
+func (registry *envCommandRegistry) evaluate(command envCommand, filter EnvFilter) (string, error) {
+	// Variables of the context can be dynamic too
+	contextEnvVars, err := registry.resolve(command.context.envVars, filter)
+	if err != nil {
+		return "", err
+	}
+
+	environ := filter.apply(os.Environ(), contextEnvVars)
+	shell := (&ProjectCommand{Shell: command.context.shell}).getShell()
+	cacheKey := strings.Join([]string{command.context.dir, shell, command.script, hashEnvironment(environ)}, "\x00")
+
+	envCommandMutex.Lock()
+	result, exists := envCommandResults[cacheKey]
+	if !exists {
+		result = &envCommandResult{}
+		envCommandResults[cacheKey] = result
+	}
+	envCommandMutex.Unlock()
+
+	result.once.Do(func() {
+		output, err := runEnvCommand(command, shell, environ)
+		if err != nil {
+			result.err = fmt.Errorf("Failed to evaluate environment variable `%s` of %s: %v", command.name, command.owner, err)
+			return
+		}
+
+		if command.secret || isSecretEnvName(command.name) {
+			logger.RegisterSecret(command.name, output, command.secret)
+		}
+		result.output = output
+	})
+
+	// Failed commands are executed again by the next command using them
+	if result.err != nil {
+		envCommandMutex.Lock()
+		if envCommandResults[cacheKey] == result {
+			delete(envCommandResults, cacheKey)
+		}
+		envCommandMutex.Unlock()
+	}
+
+	return result.output, result.err
+}
+
+// hashEnvironment returns a hash of the sorted variables of an environment
+func hashEnvironment(environ []string) string {
+	sorted := slices.Clone(environ)
+	slices.Sort(sorted)
+
+	hash := sha256.Sum256([]byte(strings.Join(sorted, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+// runEnvCommand executes the command of a dynamic environment variable and returns its output
+func runEnvCommand(command envCommand, shell string, environ []string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), command.timeout)
+	defer cancel()
+
+	shellArgs, err := getShellArgs(shell, []string{command.script})
+	if err != nil {
+		return "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+	evalCmd := exec.CommandContext(ctx, shellArgs[0], shellArgs[1:]...)
+	evalCmd.Dir = command.context.dir
+	evalCmd.Env = environ
+	evalCmd.Stdout = &stdout
+	evalCmd.Stderr = &stderr
+	evalCmd.WaitDelay = time.Second // Don't wait for processes left holding the output
+
+	err = evalCmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("`%s` did not finish within %s", command.script, formatTimeout(command.timeout))
+	}
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", fmt.Errorf("`%s` could not be executed: %v", command.script, err)
+		}
+
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("`%s` failed with exit code %d: %s", command.script, exitErr.ExitCode(), message)
+		}
+		return "", fmt.Errorf("`%s` failed with exit code %d", command.script, exitErr.ExitCode())
+	}
+
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// resolveDynamicEnv evaluates the dynamic environment variables of a command that starts, and of its hooks
+func (cmd *ProjectCommand) resolveDynamicEnv() error {
+	var err error
+	if cmd.EnvVars, err = cmd.EnvCommands.resolve(cmd.EnvVars, cmd.EnvFilter); err != nil {
+		return err
+	}
+
+	hooks := []*ProjectCommand{
+		cmd.PreCommand, cmd.PostCommand, cmd.AfterCommand, cmd.AfterSuccessCommand,
+		cmd.AfterFailureCommand, cmd.AfterAlwaysCommand, cmd.AfterChangeCommand,
+		cmd.ProjPreCommand, cmd.ProjPostCommand, cmd.ProjAfterCommand,
+	}
+
+	for _, hook := range hooks {
+		if hook != nil {
+			if err := hook.resolveDynamicEnv(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package navi
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// envLayer resolves the references of a layer of environment variables, like a dotenv file or an `env` map
+type envLayer struct {
+	values    map[string]string // Values as written
+	resolved  map[string]string // Expanded values
+	previous  map[string]string // Variables of the previous layers
+	resolving []string          // Variables being expanded, to detect cycles
+}
+
+// expandEnvLayer expands the `${NAME}` references in the values of a layer. A reference to another variable
+// of the layer uses its expanded value, then the previous layers and the environment of navi are checked.
+// A variable referencing itself, as in `PATH=./bin:${PATH}`, gets the value of the previous layers.
+// Values of `literals`, like the outputs of commands, are kept as they are
+func expandEnvLayer(values, literals map[string]string, previousEnvVars []string) (map[string]string, error) {
+	layer := envLayer{
+		values:   values,
+		resolved: make(map[string]string, len(values)+len(literals)),
+		previous: make(map[string]string, len(previousEnvVars)),
+	}
+
+	maps.Copy(layer.resolved, literals)
+	for _, envVar := range previousEnvVars {
+		if key, value, found := strings.Cut(envVar, "="); found {
+			layer.previous[key] = value
+		}
+	}
+
+	// Sorted keys report the same cycle on each run
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if _, err := layer.resolve(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return layer.resolved, nil
+}
+
+// resolve returns the expanded value of a variable of the layer
+func (layer *envLayer) resolve(key string) (string, error) {
+	if value, exists := layer.resolved[key]; exists {
+		return value, nil
+	}
+
+	if index := slices.Index(layer.resolving, key); index >= 0 {
+		cycle := append(slices.Clone(layer.resolving[index:]), key)
+		return "", fmt.Errorf("Cyclic reference between environment variables `%s`", strings.Join(cycle, "` -> `"))
+	}
+
+	layer.resolving = append(layer.resolving, key)
+	defer func() { layer.resolving = layer.resolving[:len(layer.resolving)-1] }()
+
+	var resolveErr error
+	value := expandReferences(layer.values[key], func(name string) (string, bool) {
+		if resolveErr != nil {
+			return "", false
+		}
+
+		if _, inLayer := layer.values[name]; inLayer && name != key {
+			value, err := layer.resolve(name)
+			resolveErr = err
+			return value, err == nil
+		}
+
+		if value, exists := layer.resolved[name]; exists && name != key {
+			return value, true
+		}
+
+		if value, exists := layer.previous[name]; exists {
+			return value, true
+		}
+
+		return os.LookupEnv(name)
+	})
+
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+
+	layer.resolved[key] = value
+	return value, nil
+}
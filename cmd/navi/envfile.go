@@ -0,0 +1,147 @@
+package navi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+)
+
+// Formats of the files loaded by `dotenv`
+var envFileFormats = []string{"dotenv", "json", "yaml", "toml"}
+
+// Separator joining the nested keys of structured environment files, as in `DB__HOST`
+const defaultEnvKeySeparator = "__"
+
+// getFormat returns the format set for the file, or the one matching its extension
+func (file DotEnvFile) getFormat() string {
+	if file.Format != "" {
+		return file.Format
+	}
+
+	switch strings.ToLower(filepath.Ext(file.Path)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	}
+
+	return "dotenv"
+}
+
+// readEnvFile reads the variables of an environment file. Nested keys of JSON, YAML and TOML
+// files are joined with the separator of the file, and list items are keyed by their index
+func readEnvFile(file DotEnvFile) (map[string]string, error) {
+	format := file.getFormat()
+	if !slices.Contains(envFileFormats, format) {
+		return nil, fmt.Errorf("Invalid format `%s`. Must be one of `%s`", format, strings.Join(envFileFormats, "`, `"))
+	}
+
+	if format == "dotenv" {
+		return readDotEnvFile(file.Path)
+	}
+
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var content any
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber() // Keep numbers as written, like large integers
+		err = decoder.Decode(&content)
+	case "yaml":
+		err = yaml.Unmarshal(data, &content)
+	case "toml":
+		var tomlContent map[string]any
+		_, err = toml.Decode(string(data), &tomlContent)
+		content = tomlContent
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s content: %v", strings.ToUpper(format), err)
+	}
+
+	fileEnv := make(map[string]string)
+	if content == nil {
+		return fileEnv, nil
+	}
+
+	if _, isMap := content.(map[string]any); !isMap {
+		return nil, fmt.Errorf("The content must be a map of variables")
+	}
+
+	separator := file.Separator
+	if separator == "" {
+		separator = defaultEnvKeySeparator
+	}
+
+	flattenEnvValues(fileEnv, "", content, separator)
+	return fileEnv, nil
+}
+
+// readDotEnvFile reads a dotenv file without expanding its references, which are resolved along with the
+// other variables of the command. Dollar signs are hidden from the parser, which empties unknown variables
+func readDotEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	content := strings.ReplaceAll(string(data), "\\$", "\uF001")
+	content = strings.ReplaceAll(content, "$", "\uF003")
+
+	fileEnv, err := godotenv.Unmarshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range fileEnv {
+		value = strings.ReplaceAll(value, "\uF003", "$")
+		fileEnv[key] = strings.ReplaceAll(value, "\uF001", "\\$")
+	}
+
+	return fileEnv, nil
+}
+
+// flattenEnvValues adds the scalar values of a structured value, keyed by their path
+func flattenEnvValues(fileEnv map[string]string, key string, value any, separator string) {
+	joinKey := func(name string) string {
+		if key == "" {
+			return name
+		}
+		return key + separator + name
+	}
+
+	switch typedValue := value.(type) {
+	case map[string]any:
+		for name, item := range typedValue {
+			flattenEnvValues(fileEnv, joinKey(name), item, separator)
+		}
+	case []any:
+		for i, item := range typedValue {
+			flattenEnvValues(fileEnv, joinKey(strconv.Itoa(i)), item, separator)
+		}
+	case []map[string]any: // TOML arrays of tables
+		for i, item := range typedValue {
+			flattenEnvValues(fileEnv, joinKey(strconv.Itoa(i)), item, separator)
+		}
+	case time.Time:
+		fileEnv[key] = typedValue.Format(time.RFC3339)
+	default:
+		fileEnv[key] = convertYamlValueToString(typedValue)
+	}
+}
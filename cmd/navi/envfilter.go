@@ -0,0 +1,119 @@
+package navi
+
+import (
+	"os"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// withSettings returns the filter overridden by the `inherit_env` and `unset_env` of a more specific level.
+// `inherit_env` replaces the one of the parent, while the unset variables add up
+func (filter EnvFilter) withSettings(inheritEnv, unsetEnv any) (EnvFilter, bool) {
+	result := filter
+
+	switch value := inheritEnv.(type) {
+	case nil:
+	case bool:
+		result.Restricted = !value
+		result.Inherit = nil
+	default:
+		patterns, ok := convertToStringList(value)
+		if !ok {
+			return filter, false
+		}
+		result.Restricted = true
+		result.Inherit = patterns
+	}
+
+	switch value := unsetEnv.(type) {
+	case nil:
+	case []string:
+		result.Unset = slices.Concat(filter.Unset, value)
+	default:
+		patterns, ok := convertToStringList(value)
+		if !ok {
+			return filter, false
+		}
+		result.Unset = slices.Concat(filter.Unset, patterns)
+	}
+
+	return result, true
+}
+
+// apply returns the environment of a process: the inherited variables of navi followed by
+// the ones set for the command, without the unset ones
+func (filter EnvFilter) apply(environ []string, envVars []string) []string {
+	result := []string{}
+	for _, envVar := range environ {
+		name, _, _ := strings.Cut(envVar, "=")
+		if !filter.Restricted || matchesEnvNamePattern(filter.Inherit, name) {
+			result = append(result, envVar)
+		}
+	}
+
+	result = append(result, envVars...)
+	return slices.DeleteFunc(result, func(envVar string) bool {
+		name, _, _ := strings.Cut(envVar, "=")
+		return filter.isUnset(name)
+	})
+}
+
+// isUnset checks if a variable is removed from the environment
+func (filter EnvFilter) isUnset(name string) bool {
+	return matchesEnvNamePattern(filter.Unset, name)
+}
+
+// getPlanInheritEnv returns the inherited variables shown by `--dry-run` and `navi inspect` (nil = all)
+func (filter EnvFilter) getPlanInheritEnv() *[]string {
+	if !filter.Restricted {
+		return nil
+	}
+
+	patterns := append([]string{}, filter.Inherit...)
+	return &patterns
+}
+
+// matchesEnvNamePattern checks if a variable name matches one of the patterns, like `AWS_*`.
+// Names are case-insensitive on Windows
+func matchesEnvNamePattern(patterns []string, name string) bool {
+	if runtime.GOOS == "windows" {
+		name = strings.ToUpper(name)
+	}
+
+	for _, pattern := range patterns {
+		if runtime.GOOS == "windows" {
+			pattern = strings.ToUpper(pattern)
+		}
+
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getProcessEnv returns the environment of the processes of the command
+func (cmd *ProjectCommand) getProcessEnv() []string {
+	envVars := append(slices.Clone(cmd.EnvVars), forceColorEnvVar) // Enable colors in output
+	return cmd.EnvFilter.apply(os.Environ(), envVars)
+}
+
+// applyEnvFilter resolves the environment filter of the command and of its hooks from the one of its parent
+func (cmd *ProjectCommand) applyEnvFilter(parentFilter EnvFilter) {
+	// Settings of the command are checked when it is built
+	cmd.EnvFilter, _ = parentFilter.withSettings(cmd.InheritEnv, cmd.UnsetEnv)
+
+	hooks := []*ProjectCommand{
+		cmd.PreCommand, cmd.PostCommand, cmd.AfterCommand, cmd.AfterSuccessCommand,
+		cmd.AfterFailureCommand, cmd.AfterAlwaysCommand, cmd.AfterChangeCommand,
+	}
+
+	for _, hook := range hooks {
+		if hook != nil {
+			hook.applyEnvFilter(cmd.EnvFilter)
+		}
+	}
+}
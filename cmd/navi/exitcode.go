@@ -0,0 +1,98 @@
+package navi
+
+import (
+	"errors"
+	"os/exec"
+	"slices"
+	"sync"
+	"syscall"
+)
+
+// Exit codes of navi, besides the ones of the failed commands
+const (
+	exitCodeFailure     = 1   // Failure without exit code, like a configuration error
+	exitCodeTimeout     = 124 // Command timed out, as reported by `timeout`
+	exitCodeInterrupted = 130 // Shutdown on SIGINT
+	exitCodeTerminated  = 143 // Shutdown on SIGTERM
+)
+
+// Accepted policies choosing the exit code when several commands fail
+var exitCodePolicies = []string{"first", "last", "max"}
+
+// Policy set with `--exit-code`
+var exitCodePolicy = "first"
+
+// Exit code of the failures recorded so far (0 = no failure)
+var recordedExitCode int
+var recordedExitCodeMutex sync.Mutex
+
+// commandFailureError reports a failure with its own message, keeping the exit code of its cause
+type commandFailureError struct {
+	message string
+	cause   error
+}
+
+// Error returns the message of the failure
+func (err *commandFailureError) Error() string {
+	return err.message
+}
+
+// Unwrap returns the error that caused the failure
+func (err *commandFailureError) Unwrap() error {
+	return err.cause
+}
+
+// isValidExitCodePolicy checks if a policy is one of the accepted ones
+func isValidExitCodePolicy(policy string) bool {
+	return slices.Contains(exitCodePolicies, policy)
+}
+
+// getErrorExitCode returns the exit code reported for an error
+func getErrorExitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal()) // Like shells, for commands killed by a signal
+		}
+
+		if exitErr.ExitCode() > 0 {
+			return exitErr.ExitCode()
+		}
+	}
+
+	if errors.Is(err, ErrCommandTimeout) {
+		return exitCodeTimeout
+	}
+
+	return exitCodeFailure
+}
+
+// recordFailure keeps the exit code of a failure, according to the exit code policy
+func recordFailure(err error) {
+	exitCode := getErrorExitCode(err)
+
+	recordedExitCodeMutex.Lock()
+	defer recordedExitCodeMutex.Unlock()
+
+	switch {
+	case recordedExitCode == 0,
+		exitCodePolicy == "last",
+		exitCodePolicy == "max" && exitCode > recordedExitCode:
+		recordedExitCode = exitCode
+	}
+}
+
+// getExitCode returns the exit code of navi, based on the failures recorded so far
+// or on the signal that triggered the shutdown
+func getExitCode(signal string) int {
+	switch signal {
+	case "interrupt":
+		return exitCodeInterrupted
+	case "terminated":
+		return exitCodeTerminated
+	}
+
+	recordedExitCodeMutex.Lock()
+	defer recordedExitCodeMutex.Unlock()
+	return recordedExitCode
+}
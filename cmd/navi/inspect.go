@@ -0,0 +1,181 @@
+package navi
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/go-navi/navi/internal/logger"
+)
+
+// Version of the output of `navi inspect`, increased on breaking changes
+const inspectSchemaVersion = 1
+
+// Formats accepted by `navi inspect --format`
+var inspectFormats = []string{"json", "yaml"}
+
+// inspectOutput is the document printed by `navi inspect`
+type inspectOutput struct {
+	Version  int            `json:"version"`
+	Commands []commandPlan  `json:"commands"`
+	Runners  []runnerPlan   `json:"runners"`
+	Errors   []inspectError `json:"errors,omitempty"`
+}
+
+// inspectError reports a command or runner that could not be resolved by `navi inspect --all`
+type inspectError struct {
+	Target string `json:"target"`
+	Error  string `json:"error"`
+}
+
+// runInspectCommand prints the resolved commands and runners of the configuration
+func runInspectCommand(args []string) {
+	targets, format, inspectAll, err := parseInspectArgs(args)
+	if err != nil {
+		logger.Error("%v", err)
+		os.Exit(1)
+	}
+
+	output := inspectOutput{
+		Version:  inspectSchemaVersion,
+		Commands: []commandPlan{},
+		Runners:  []runnerPlan{},
+	}
+
+	if inspectAll {
+		err = output.addAll()
+	} else {
+		err = output.addTarget(targets)
+	}
+
+	if err != nil {
+		logger.Error("%v", err)
+		os.Exit(1)
+	}
+
+	data, err := output.encode(format)
+	if err != nil {
+		logger.Error("Failed to generate inspect output: %v", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(logger.MaskSecrets(string(data)))
+	os.Exit(0)
+}
+
+// parseInspectArgs reads the target and the options of `navi inspect`, which can be given in any order
+func parseInspectArgs(args []string) (targets []string, format string, inspectAll bool, err error) {
+	flagSet := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+	flagSet.StringVar(&format, "format", "json", "")
+	flagSet.BoolVar(&inspectAll, "all", false, "")
+
+	for {
+		if err := flagSet.Parse(args); err != nil {
+			return nil, "", false, fmt.Errorf("Invalid option for `inspect`: %v", err)
+		}
+
+		if flagSet.NArg() == 0 {
+			break
+		}
+
+		targets = append(targets, flagSet.Arg(0))
+		args = flagSet.Args()[1:]
+	}
+
+	if !slices.Contains(inspectFormats, format) {
+		return nil, "", false, fmt.Errorf("Invalid value `%s` for `--format`. Must be one of `%s`", format, strings.Join(inspectFormats, "`, `"))
+	}
+
+	if inspectAll && len(targets) > 0 {
+		return nil, "", false, fmt.Errorf("Option `--all` of `inspect` can't be combined with a command or runner")
+	}
+
+	if !inspectAll && len(targets) == 0 {
+		return nil, "", false, fmt.Errorf("Missing command or runner to inspect. Use `navi inspect <target>` or `navi inspect --all`")
+	}
+
+	return targets, format, inspectAll, nil
+}
+
+// addTarget adds the runner or command matching the arguments
+func (output *inspectOutput) addTarget(args []string) error {
+	plan, err := buildExecutionPlan(args, RunnerFlags{})
+	if err != nil {
+		return err
+	}
+
+	switch plan := plan.(type) {
+	case runnerPlan:
+		output.Runners = append(output.Runners, plan)
+	case commandPlan:
+		output.Commands = append(output.Commands, plan)
+	}
+
+	return nil
+}
+
+// addAll adds every command, project command and runner of the configuration, sorted by name.
+// The ones that can't be resolved, like commands with required parameters, are reported as errors
+func (output *inspectOutput) addAll() error {
+	yamlConfig, _, err := getYamlConfiguration(true)
+	if err != nil {
+		return fmt.Errorf("Failed to load configuration from YAML file: %v", err)
+	}
+
+	commandIds := slices.Sorted(maps.Keys(yamlConfig.Commands))
+	for _, projectName := range slices.Sorted(maps.Keys(yamlConfig.Projects)) {
+		for _, cmdName := range slices.Sorted(maps.Keys(yamlConfig.Projects[projectName].Cmds)) {
+			commandIds = append(commandIds, projectName+":"+cmdName)
+		}
+	}
+
+	for _, commandId := range commandIds {
+		plan, err := buildCommandPlan([]string{commandId})
+		if err != nil {
+			output.Errors = append(output.Errors, inspectError{Target: commandId, Error: err.Error()})
+			continue
+		}
+		output.Commands = append(output.Commands, plan)
+	}
+
+	// Runners are resolved by their name, without the flags of their key
+	runnerNames := []string{}
+	for runnerKey := range yamlConfig.Runners {
+		runnerName, _ := extractRunnerNameAndFlags(runnerKey, []string{})
+		runnerNames = append(runnerNames, runnerName)
+	}
+	slices.Sort(runnerNames)
+
+	for _, runnerName := range runnerNames {
+		plan, err := buildRunnerPlan([]string{runnerName}, RunnerFlags{})
+		if err != nil {
+			output.Errors = append(output.Errors, inspectError{Target: runnerName, Error: err.Error()})
+			continue
+		}
+		output.Runners = append(output.Runners, plan)
+	}
+
+	return nil
+}
+
+// encode serializes the output in the requested format
+func (output *inspectOutput) encode(format string) ([]byte, error) {
+	if format == "yaml" {
+		return yaml.MarshalWithOptions(output, yaml.IndentSequence(true))
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,323 @@
+package navi
+
+import "github.com/go-navi/navi/internal/utils"
+
+// valueKind describes the accepted shape of a configuration value
+type valueKind int
+
+const (
+	stringValue             valueKind = iota // Plain string
+	numberValue                              // Integer or decimal number
+	integerValue                             // Integer number
+	durationValue                            // Duration like `5m`, or number of seconds
+	boolValue                                // `true` or `false`
+	scalarValue                              // String, number or boolean
+	stringListValue                          // String or list of strings
+	runValue                                 // Command or list of commands and detailed steps
+	exitCodesValue                           // Exit code or list of exit codes
+	dotenvValue                              // Env file path, detailed env file or list of them, with optional `| KEYS`
+	envValue                                 // Map of environment variables
+	varsValue                                // Map of template variables
+	inheritEnvValue                          // `true`, `false` or list of variable name patterns
+	paramsValue                              // List of command parameters
+	watchValue                               // Glob patterns or `include`/`exclude` map
+	commandValue                             // Command string, list of commands or detailed command
+	afterCommandValue                        // Command or map of `after.*` hooks
+	commandMapValue                          // Map of named commands
+	commandOverrideMapValue                  // Map of command overrides, without required keys
+	projectMapValue                          // Map of named projects
+	projectOverrideMapValue                  // Map of project overrides, without required keys
+	profileMapValue                          // Map of named profiles
+	templateMapValue                         // Map of named project templates
+	runnerMapValue                           // Map of named runners
+	restartValue                             // Boolean or restart settings map
+	awaitsValue                              // Port, list of ports or awaits settings map
+	portsValue                               // Port or list of ports
+	conditionValue                           // Map of conditions, or list of conditions that must all be met
+	conditionListValue                       // List of conditions
+)
+
+// configKey describes a key accepted in a configuration map
+type configKey struct {
+	name        string    // Key name
+	kind        valueKind // Accepted value shape
+	required    bool      // Whether the key must be present
+	allowed     []string  // Accepted values (empty = any)
+	description string    // Short description, used in the JSON schema
+}
+
+// Accepted restart conditions
+var restartConditions = []string{"failure", "success", "always"}
+
+// Accepted runner flags, written as `runner[flag1,flag2]`
+var runnerFlagNames = []string{"serial", "dependent"}
+
+// Keys accepted at the top level of a configuration file
+var topLevelKeys = []configKey{
+	{name: "include", kind: stringListValue, description: "Configuration files to merge, relative to this file. Glob patterns are supported"},
+	{name: "vars", kind: varsValue, description: "Variables referenced as `{{ .vars.name }}`"},
+	{name: "secrets", kind: stringListValue, description: "Name patterns of the environment variables masked as `***` in the output, like `*_TOKEN`"},
+	{name: "inherit_env", kind: inheritEnvValue, description: "Variables inherited from the environment of Navi: `true` (default), `false` or name patterns like `[PATH, HOME]`"},
+	{name: "unset_env", kind: stringListValue, description: "Name patterns of the variables removed from the environment, like `NODE_OPTIONS` or `FORCE_COLOR`"},
+	{name: "commands", kind: commandMapValue, description: "Global commands, executed from the root folder"},
+	{name: "templates", kind: templateMapValue, description: "Settings and commands shared by the projects referencing them with `template`"},
+	{name: "projects", kind: projectMapValue, description: "Projects grouping commands with shared settings"},
+	{name: "runners", kind: runnerMapValue, description: "Runners executing several commands, optionally with `[serial,dependent]` flags"},
+	{name: "profiles", kind: profileMapValue, description: "Overrides deep-merged onto the configuration when selected with `--profile`"},
+}
+
+// Keys accepted by a profile
+var profileKeys = []configKey{
+	{name: "vars", kind: varsValue, description: "Variables to add or override"},
+	{name: "commands", kind: commandOverrideMapValue, description: "Global commands to add or override"},
+	{name: "projects", kind: projectOverrideMapValue, description: "Project settings to add or override"},
+	{name: "runners", kind: runnerMapValue, description: "Runners to add or replace"},
+}
+
+// Keys accepted by a project
+var projectKeys = []configKey{
+	{name: "dir", kind: stringValue, required: true, description: "Working directory of the project"},
+	{name: "template", kind: stringValue, description: "Template whose settings and commands the project receives"},
+	{name: "shell", kind: stringValue, description: "Shell used to execute the commands"},
+	{name: "watch", kind: watchValue, description: "File patterns that restart the commands when changed"},
+	{name: "dotenv", kind: dotenvValue, description: "Environment files to load"},
+	{name: "env", kind: envValue, description: "Environment variables"},
+	{name: "inherit_env", kind: inheritEnvValue, description: "Variables inherited from the environment of Navi: `true` (default), `false` or name patterns like `[PATH, HOME]`"},
+	{name: "unset_env", kind: stringListValue, description: "Name patterns of the variables removed from the environment, like `NODE_OPTIONS` or `FORCE_COLOR`"},
+	{name: "vars", kind: varsValue, description: "Variables referenced as `{{ .vars.name }}`, overriding the global ones"},
+	{name: "pre", kind: commandValue, description: "Command executed before every command"},
+	{name: "post", kind: commandValue, description: "Command executed after every successful command"},
+	{name: "after", kind: afterCommandValue, description: "Command executed after every command, depending on its result"},
+	{name: "cmds", kind: commandMapValue, description: "Project commands"},
+}
+
+// Keys accepted by a project template, the ones of a project except `dir` and `template`
+var templateKeys = excludeConfigKeys(projectKeys, "dir", "template")
+
+// Keys accepted by a detailed command
+var commandKeys = []configKey{
+	{name: "extends", kind: stringValue, description: "Command whose `run`, `env`, `dotenv`, `watch`, `shell` and hooks are inherited, as `name` or `project:command`"},
+	{name: "dir", kind: stringValue, description: "Working directory, relative to the parent directory"},
+	{name: "shell", kind: stringValue, description: "Shell used to execute the command"},
+	{name: "watch", kind: watchValue, description: "File patterns that restart the command when changed"},
+	{name: "dotenv", kind: dotenvValue, description: "Environment files to load"},
+	{name: "env", kind: envValue, description: "Environment variables"},
+	{name: "inherit_env", kind: inheritEnvValue, description: "Variables inherited from the environment of Navi: `true` (default), `false` or name patterns like `[PATH, HOME]`"},
+	{name: "unset_env", kind: stringListValue, description: "Name patterns of the variables removed from the environment, like `NODE_OPTIONS` or `FORCE_COLOR`"},
+	{name: "vars", kind: varsValue, description: "Variables referenced as `{{ .vars.name }}`, overriding the parent ones"},
+	{name: "params", kind: paramsValue, description: "Parameters given as `--name=value` or positional arguments, referenced as `{{ .params.name }}`"},
+	{name: "deps", kind: stringListValue, description: "Commands executed once before this one, in parallel when independent, as `name` or `project:command`"},
+	{name: "sources", kind: stringListValue, description: "File patterns whose content is hashed to skip the command when unchanged"},
+	{name: "outputs", kind: stringListValue, description: "File patterns that must exist to skip the command when its sources are unchanged"},
+	{name: "timeout", kind: durationValue, description: "Maximum execution time, like `30s` or `5m`, before the command is terminated"},
+	{name: "if", kind: conditionValue, description: "Conditions that must be met to execute the command, otherwise it is skipped"},
+	{name: "interactive", kind: boolValue, description: "Whether the command is attached to the terminal, to read input and use prompts"},
+	{name: "shared_shell", kind: boolValue, description: "Whether the steps of `run` share a single shell, to keep state like `cd` between them"},
+	{name: "continue_on_error", kind: boolValue, description: "Whether the next steps run and the command succeeds when a step fails"},
+	{name: "ok_exit_codes", kind: exitCodesValue, description: "Exit codes of the steps accepted besides 0, like `[0, 1]`"},
+	{name: "pre", kind: commandValue, description: "Command executed before the main command"},
+	{name: "run", kind: runValue, required: true, description: "Command or list of commands and steps to execute"},
+	{name: "post", kind: commandValue, description: "Command executed after the main command succeeds"},
+	{name: "after", kind: afterCommandValue, description: "Command executed after the main command, depending on its result"},
+}
+
+// Keys accepted by a detailed `run` step
+var stepKeys = []configKey{
+	{name: "run", kind: stringValue, required: true, description: "Command to execute"},
+	{name: "continue_on_error", kind: boolValue, description: "Whether the next steps run and the command succeeds when this step fails"},
+	{name: "ok_exit_codes", kind: exitCodesValue, description: "Exit codes accepted besides 0, like `[0, 1]`"},
+}
+
+// Keys accepted by an `after` hook
+var afterKeys = []configKey{
+	{name: "success", kind: commandValue, description: "Command executed when the main command succeeds"},
+	{name: "failure", kind: commandValue, description: "Command executed when the main command fails"},
+	{name: "change", kind: commandValue, description: "Command executed when the main command is restarted by a file change"},
+	{name: "always", kind: commandValue, description: "Command always executed after the main command"},
+}
+
+// Keys accepted by a command parameter
+var paramKeys = []configKey{
+	{name: "name", kind: stringValue, required: true, description: "Parameter name, also exported as an upper-cased environment variable"},
+	{name: "description", kind: stringValue, description: "Description shown in the interactive CLI"},
+	{name: "default", kind: scalarValue, description: "Value used when none is given"},
+	{name: "required", kind: boolValue, description: "Whether a value must be given"},
+	{name: "allowed", kind: stringListValue, description: "Accepted values"},
+}
+
+// Keys accepted by a detailed `dotenv` file
+var dotenvFileKeys = []configKey{
+	{name: "path", kind: stringValue, required: true, description: "Path of the file, optionally followed by `| KEY1, KEY2` to load only some keys"},
+	{name: "format", kind: stringValue, allowed: envFileFormats, description: "Format of the file (default: detected from the extension, `dotenv` otherwise)"},
+	{name: "separator", kind: stringValue, description: "Separator joining the nested keys of JSON, YAML and TOML files (default: `__`)"},
+	{name: "secret", kind: boolValue, description: "Whether the loaded values are masked as `***` in the output"},
+}
+
+// Keys accepted by a detailed environment variable
+var envVarKeys = []configKey{
+	{name: "value", kind: scalarValue, description: "Value of the variable"},
+	{name: "sh", kind: stringValue, description: "Command whose output is the value, executed once in the directory and shell of the command"},
+	{name: "timeout", kind: durationValue, description: "Maximum execution time of `sh`, like `30s` (default: 10s)"},
+	{name: "secret", kind: boolValue, description: "Whether the value is masked as `***` in the output"},
+}
+
+// Keys accepted by a detailed `watch` setting
+var watchKeys = []configKey{
+	{name: "include", kind: stringListValue, description: "File patterns to watch"},
+	{name: "exclude", kind: stringListValue, description: "File patterns to ignore"},
+}
+
+// Keys accepted by a detailed runner command
+var runnerCommandKeys = []configKey{
+	{name: "cmd", kind: stringValue, required: true, description: "Command, project command or `project:*` to execute"},
+	{name: "name", kind: stringValue, description: "Name displayed in the logs"},
+	{name: "serial", kind: boolValue, description: "Whether the next commands wait for this one to finish"},
+	{name: "dependent", kind: boolValue, description: "Whether all commands stop if this one fails"},
+	{name: "delay", kind: numberValue, description: "Seconds to wait before starting"},
+	{name: "timeout", kind: durationValue, description: "Maximum execution time, like `30s` or `5m`, before the command is terminated"},
+	{name: "if", kind: conditionValue, description: "Conditions that must be met to execute the command, otherwise it is skipped"},
+	{name: "restart", kind: restartValue, description: "Restart behavior when the command finishes"},
+	{name: "awaits", kind: awaitsValue, description: "Ports that must accept connections before starting"},
+}
+
+// Keys accepted by a detailed `restart` setting
+var restartKeys = []configKey{
+	{name: "retries", kind: integerValue, description: "Maximum restart attempts (default: infinite)"},
+	{name: "interval", kind: numberValue, description: "Seconds between restarts (default: 1)"},
+	{name: "condition", kind: stringValue, allowed: restartConditions, description: "When to restart (default: failure)"},
+}
+
+// Keys accepted by a detailed `awaits` setting
+var awaitsKeys = []configKey{
+	{name: "ports", kind: portsValue, description: "Port or list of ports to wait for"},
+	{name: "timeout", kind: numberValue, description: "Seconds to wait before failing (default: 30)"},
+}
+
+// Keys accepted by an `if` condition, all of which must be met
+var conditionKeys = []configKey{
+	{name: "env", kind: stringListValue, description: "Environment variables that must be set, or equal a value when written as `NAME=value`"},
+	{name: "exists", kind: stringListValue, description: "Paths or file patterns that must exist, relative to the command directory"},
+	{name: "os", kind: stringListValue, description: "Operating systems on which the command runs, like `linux`, `darwin` or `windows`"},
+	{name: "cmd", kind: stringValue, description: "Probe command that must succeed"},
+	{name: "all", kind: conditionListValue, description: "Conditions that must all be met"},
+	{name: "any", kind: conditionListValue, description: "Conditions of which at least one must be met"},
+	{name: "not", kind: conditionValue, description: "Condition that must not be met"},
+}
+
+// Expected values of the kinds checked when parsing a command, the other kinds
+// being checked by the functions parsing them
+var parsedValueKindDescriptions = map[valueKind]string{
+	stringValue:     "a string",
+	boolValue:       "`true` or `false`",
+	durationValue:   "a duration like `30s` or `5m`, or a number of seconds",
+	stringListValue: "a string or a list of strings",
+	exitCodesValue:  "an exit code or a list of exit codes",
+}
+
+// matchesValueKind checks if a parsed value has the shape expected by a kind of `parsedValueKindDescriptions`
+func matchesValueKind(value any, kind valueKind) bool {
+	switch kind {
+	case stringValue:
+		_, ok := value.(string)
+		return ok
+	case boolValue:
+		_, ok := value.(bool)
+		return ok
+	case durationValue:
+		_, ok := parseTimeout(value)
+		return ok
+	case stringListValue:
+		_, ok := convertToStringList(value)
+		return ok
+	case exitCodesValue:
+		_, ok := parseStepPolicy(map[string]any{"ok_exit_codes": value}, StepPolicy{})
+		return ok
+	}
+	return true
+}
+
+// findConfigKey returns the definition of a key in a list of accepted keys
+func findConfigKey(keys []configKey, name string) (configKey, bool) {
+	for _, key := range keys {
+		if key.name == name {
+			return key, true
+		}
+	}
+	return configKey{}, false
+}
+
+// getNestedConfigKeys returns the keys of the settings map a value kind accepts
+func getNestedConfigKeys(kind valueKind) []configKey {
+	switch kind {
+	case watchValue:
+		return watchKeys
+	case afterCommandValue:
+		return afterKeys
+	case restartValue:
+		return restartKeys
+	case awaitsValue:
+		return awaitsKeys
+	case conditionValue:
+		return conditionKeys
+	case runValue:
+		return stepKeys
+	case envValue:
+		return envVarKeys
+	case dotenvValue:
+		return dotenvFileKeys
+	}
+	return nil
+}
+
+// excludeConfigKeys returns a copy of a list of keys without some of them
+func excludeConfigKeys(keys []configKey, excludedNames ...string) []configKey {
+	var remainingKeys []configKey
+	for _, key := range keys {
+		if !utils.SliceContainsValue(excludedNames, key.name) {
+			remainingKeys = append(remainingKeys, key)
+		}
+	}
+	return remainingKeys
+}
+
+// getOverrideConfigKeys returns the keys accepted when overriding settings, where no key is required
+func getOverrideConfigKeys(keys []configKey) []configKey {
+	overrideKeys := make([]configKey, len(keys))
+	for i, key := range keys {
+		key.required = false
+		switch key.kind {
+		case commandMapValue:
+			key.kind = commandOverrideMapValue
+		case projectMapValue:
+			key.kind = projectOverrideMapValue
+		}
+		overrideKeys[i] = key
+	}
+	return overrideKeys
+}
+
+// getOrderedConfigKeyNames returns all key names in the order they are displayed
+func getOrderedConfigKeyNames() []string {
+	var names []string
+
+	for _, keys := range [][]configKey{commandKeys, projectKeys, runnerCommandKeys} {
+		for _, key := range keys {
+			for _, name := range append([]string{key.name}, getConfigKeyNames(getNestedConfigKeys(key.kind))...) {
+				if !utils.SliceContainsValue(names, name) {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+// getConfigKeyNames returns the names of a list of keys
+func getConfigKeyNames(keys []configKey) []string {
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.name
+	}
+	return names
+}
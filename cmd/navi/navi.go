@@ -21,6 +21,15 @@ var processWg sync.WaitGroup
 
 var NaviVersion = "1.0.0"
 
+// Invocations taken over by the built-in commands, by name. A command, project or runner
+// with the same name still runs with any other arguments
+var builtinCommandUsages = map[string]string{
+	"schema":   "navi schema",
+	"validate": "navi validate",
+	"cache":    "navi cache clean",
+	"inspect":  "navi inspect",
+}
+
 var HelpText = `Navi - Lightweight Command Runner
 
 Usage:
@@ -33,6 +42,11 @@ Usage:
   navi [options] <project:command> [args...]
   navi [options] <project> [args...]
   navi [options] [<command>, <project:command>, ...]
+  navi [options] validate
+  navi [options] cache clean
+  navi [options] inspect <target> [--format json|yaml]
+  navi [options] inspect --all [--format json|yaml]
+  navi schema
 
 Examples:
   navi lint              Run predefined 'lint' single command
@@ -41,14 +55,27 @@ Examples:
   navi web go build      Run 'go build' on the 'web' project folder
   navi start-all         Run predefined 'start-all' runner
   navi lint web:dev ...  Run multiple commands or project commands
+  navi validate          Check the config file and report problems
+  navi cache clean       Remove the stored fingerprints of commands with sources
+  navi inspect web:dev   Print the resolved 'web:dev' command as JSON
+  navi schema            Print the JSON schema of the config file
 
 Options:
-  -f, --file <path>      Specify path to config file (default: ./navi.yml)
+  -f, --file <path>      Specify path to config file (default: nearest navi.yml)
+                         Repeat to merge more files, later ones take precedence
+  -p, --profile <name>   Apply the overrides of a profile defined in the config file
   -s, --serial           Run all runner commands sequentially
   -d, --dependent        Make all runner commands dependent
+      --force            Execute commands even when their sources are up to date
+      --exit-code <mode> Exit code when several commands fail: first (default), last or max
+      --dry-run          Print the execution plan without executing any command
   -h, --help             Display this help message
   -v, --version          Display current version
 
+Environment:
+  NAVI_CONFIG            Path to config file, used when --file is not set
+  NAVI_PROFILE           Profile to apply, used when --profile is not set
+
 See https://github.com/go-navi/navi for more information.`
 
 // displayHelp prints usage instructions and exits the program
@@ -73,7 +100,7 @@ func gracefulShutdown(ctx Ctx, signal string) {
 
 	// Exit immediately if no processes are running
 	if len(process.ProcessRegistry) == 0 {
-		os.Exit(1)
+		os.Exit(getExitCode(signal))
 	}
 
 	logger.Warn("Shutting down processes... (don't close the terminal)")
@@ -95,7 +122,7 @@ func gracefulShutdown(ctx Ctx, signal string) {
 	suppressNewAfterCommands = true
 	ctx.Cancel()
 	process.KillAll()
-	os.Exit(1)
+	os.Exit(getExitCode(signal))
 }
 
 // waitWithTimeout waits for a function to complete with a timeout
@@ -114,10 +141,32 @@ func waitWithTimeout(timeout time.Duration, fn func()) {
 	}
 }
 
+// Default configuration file names, in lookup order
+var configurationFileNames = []string{"navi.yml", "navi.yaml", ".navi.yml"}
+
+// fileListFlag collects the values of a flag that can be repeated
+type fileListFlag []string
+
+// String returns the collected values
+func (files *fileListFlag) String() string {
+	return strings.Join(*files, ", ")
+}
+
+// Set adds a value of the flag
+func (files *fileListFlag) Set(value string) error {
+	*files = append(*files, value)
+	return nil
+}
+
 // globalVarsInit initializes global variables based on provided flags
-func globalVarsInit(fileFlag string) error {
-	// Handle explicit config file path
-	if strings.TrimSpace(fileFlag) != "" {
+func globalVarsInit(fileFlags []string, profileFlag string) error {
+	// Fall back to the config file set in the environment
+	if len(fileFlags) == 0 && strings.TrimSpace(os.Getenv("NAVI_CONFIG")) != "" {
+		fileFlags = []string{os.Getenv("NAVI_CONFIG")}
+	}
+
+	// Handle explicit config file paths, the first one being the main file
+	for i, fileFlag := range fileFlags {
 		if info, err := os.Stat(fileFlag); os.IsNotExist(err) || info.IsDir() {
 			return fmt.Errorf("Configuration file not found. Path: %s", fileFlag)
 		}
@@ -127,32 +176,87 @@ func globalVarsInit(fileFlag string) error {
 			return fmt.Errorf("Failed to determine configuration file path: %w", err)
 		}
 
-		logger.Info("Using configuration file: %s", path)
-		configurationPath = path
+		if i == 0 {
+			logger.Info("Using configuration file: %s", path)
+			configurationPath = path
+		} else {
+			logger.Info("Using configuration overlay: %s", path)
+			overlayPaths = append(overlayPaths, path)
+		}
 	}
 
-	// Try to use default config in current directory
+	// Look for a default config file in the current directory or its parents
 	if configurationPath == "" {
-		path, err := filepath.Abs("navi.yml")
+		workingDir, err := os.Getwd()
 		if err != nil {
-			return fmt.Errorf("Failed to determine configuration file on path `./navi.yml`")
+			return fmt.Errorf("Failed to determine current directory: %w", err)
+		}
+
+		path, found := findConfigurationFile(workingDir)
+		if !found {
+			return fmt.Errorf(
+				"Configuration file not found. Looked for `%s` from `%s` up to the repository or filesystem root",
+				strings.Join(configurationFileNames, "`, `"),
+				workingDir,
+			)
 		}
 
-		if info, err := os.Stat(path); os.IsNotExist(err) || info.IsDir() {
-			return fmt.Errorf("Configuration file not found: Path: %s", path)
+		if filepath.Dir(path) != workingDir {
+			logger.Info("Using configuration file: %s", path)
 		}
 
 		configurationPath = path
 	}
 
 	applicationRootPath = strings.TrimSuffix(filepath.ToSlash(filepath.Dir(configurationPath)), "/")
+
+	// Personal settings are merged last, so they take precedence
+	localOverlayPath := filepath.Join(filepath.Dir(configurationPath), localOverlayFileName)
+	if info, err := os.Stat(localOverlayPath); err == nil && !info.IsDir() && localOverlayPath != configurationPath {
+		logger.Info("Using configuration overlay: %s", localOverlayPath)
+		overlayPaths = append(overlayPaths, localOverlayPath)
+	}
+
+	// Fall back to the profile set in the environment
+	if strings.TrimSpace(profileFlag) == "" {
+		profileFlag = os.Getenv("NAVI_PROFILE")
+	}
+
+	activeProfile = strings.TrimSpace(profileFlag)
 	return nil
 }
 
+// findConfigurationFile walks up from a directory until it finds a config file,
+// stopping at the repository root (a folder containing `.git`) or the filesystem root
+func findConfigurationFile(startDir string) (string, bool) {
+	currentDir := startDir
+
+	for {
+		for _, fileName := range configurationFileNames {
+			path := filepath.Join(currentDir, fileName)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, true
+			}
+		}
+
+		if _, err := os.Stat(filepath.Join(currentDir, ".git")); err == nil {
+			return "", false // Repository root reached
+		}
+
+		parentDir := filepath.Dir(currentDir)
+		if parentDir == currentDir {
+			return "", false // Filesystem root reached
+		}
+
+		currentDir = parentDir
+	}
+}
+
 // processCommandError handles errors and executes after commands
 func processCommandError(err error, projectCmd *ProjectCommand, ctx Ctx) {
 	if err != nil && !errors.Is(err, ErrProcessTerminated) {
 		logger.Error("%v", err)
+		recordFailure(err)
 	}
 
 	// Execute after commands if applicable
@@ -161,6 +265,7 @@ func processCommandError(err error, projectCmd *ProjectCommand, ctx Ctx) {
 		afterErr = projectCmd.executeAfterHooks(err, ctx, false)
 		if afterErr != nil {
 			logger.Error("Fail during execution of after command(s): %v", afterErr)
+			recordFailure(afterErr)
 		}
 	}
 
@@ -176,15 +281,21 @@ func processCommandError(err error, projectCmd *ProjectCommand, ctx Ctx) {
 // entry point of the application
 func Main() {
 	// Parse command-line flags - consolidate flags with shared variables
-	var fileFlag string
-	var serialFlag, dependentFlag, helpFlag, versionFlag bool
-
-	flag.StringVar(&fileFlag, "f", "", "")
-	flag.StringVar(&fileFlag, "file", "", "Specify path to config file")
+	var fileFlags fileListFlag
+	var profileFlag, exitCodeFlag string
+	var serialFlag, dependentFlag, dryRunFlag, helpFlag, versionFlag bool
+
+	flag.Var(&fileFlags, "f", "")
+	flag.Var(&fileFlags, "file", "Specify path to config file, repeat to add overlays")
+	flag.StringVar(&profileFlag, "p", "", "")
+	flag.StringVar(&profileFlag, "profile", "", "Apply the overrides of a profile")
 	flag.BoolVar(&serialFlag, "s", false, "")
 	flag.BoolVar(&serialFlag, "serial", false, "Run all runner commands serially")
 	flag.BoolVar(&dependentFlag, "d", false, "")
 	flag.BoolVar(&dependentFlag, "dependent", false, "Make all runner commands dependent")
+	flag.BoolVar(&forceExecution, "force", false, "Execute commands even when their sources are up to date")
+	flag.StringVar(&exitCodeFlag, "exit-code", "first", "Exit code used when several commands fail")
+	flag.BoolVar(&dryRunFlag, "dry-run", false, "Print the execution plan without executing commands")
 	flag.BoolVar(&helpFlag, "h", false, "")
 	flag.BoolVar(&helpFlag, "help", false, "Display help information")
 	flag.BoolVar(&versionFlag, "v", false, "")
@@ -199,13 +310,41 @@ func Main() {
 		displayVersion()
 	}
 
+	// Built-in commands that don't need a configuration file
+	args := flag.Args()
+	if len(args) == 1 && args[0] == "schema" {
+		runSchemaCommand()
+	}
+
+	if !isValidExitCodePolicy(exitCodeFlag) {
+		logger.Error("Invalid value `%s` for `--exit-code`. Must be one of `%s`", exitCodeFlag, strings.Join(exitCodePolicies, "`, `"))
+		os.Exit(1)
+	}
+	exitCodePolicy = exitCodeFlag
+
+	// The output of `inspect` is meant for other programs, so logs go to stderr
+	if len(args) > 0 && args[0] == "inspect" {
+		logger.Output = os.Stderr
+	}
+
 	// Initialize global variables
-	if err := globalVarsInit(fileFlag); err != nil {
+	if err := globalVarsInit(fileFlags, profileFlag); err != nil {
 		logger.Error("%v", err)
 		os.Exit(1)
 	}
 
-	args := flag.Args()
+	if len(args) == 1 && args[0] == "validate" {
+		runValidateCommand()
+	}
+
+	if len(args) == 2 && args[0] == "cache" && args[1] == "clean" {
+		runCacheCleanCommand()
+	}
+
+	if len(args) > 0 && args[0] == "inspect" {
+		runInspectCommand(args[1:])
+	}
+
 	if len(args) == 0 {
 		// Start interactive CLI
 		var err error
@@ -223,6 +362,16 @@ func Main() {
 		logger.Info("Selected `%s`", strings.Join(utils.AddQuotesToArgsWithSpaces(args), " "))
 	}
 
+	// Runner flags from CLI options
+	cliRunnerFlags := RunnerFlags{
+		Serial:    serialFlag,
+		Dependent: dependentFlag,
+	}
+
+	if dryRunFlag {
+		runDryRunCommand(args, cliRunnerFlags)
+	}
+
 	// Set up context and signal handling
 	commandContext := createContext(context.Background())
 	defer commandContext.Cancel()
@@ -238,16 +387,15 @@ func Main() {
 		}
 	}()
 
-	// Runner flags from CLI options
-	cliRunnerFlags := RunnerFlags{
-		Serial:    serialFlag,
-		Dependent: dependentFlag,
-	}
-
 	// Execute runner command if applicable
 	if isRunnerConfigured(args) {
 		err := executeRunner(commandContext, args, cliRunnerFlags)
 		processCommandError(err, nil, commandContext)
+
+		// Runners end with the exit code of their failed commands
+		if exitCode := getExitCode(""); exitCode != 0 && !process.TerminatingProcesses {
+			os.Exit(exitCode)
+		}
 	} else {
 		// Find and execute a global command, a project command or a project with args
 		_, _, found, _, _ := findGlobalCommandOrProjectOrProjectCommand(args)
@@ -258,6 +406,16 @@ func Main() {
 		projectCmd, _, err := getProjectCommand(args)
 		processCommandError(err, nil, commandContext)
 
+		// Execute the commands required by `deps` first
+		err = resolveCommandDependencies(projectCmd, nil)
+		processCommandError(err, nil, commandContext)
+
+		err = runCommandDependencies(commandContext, projectCmd)
+		processCommandError(err, nil, commandContext)
+
+		err = projectCmd.resolveDynamicEnv()
+		processCommandError(err, nil, commandContext)
+
 		err = projectCmd.execute(commandContext)
 		processCommandError(err, projectCmd, commandContext)
 	}
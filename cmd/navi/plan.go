@@ -0,0 +1,305 @@
+package navi
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/go-navi/navi/internal/logger"
+	portUtils "github.com/go-navi/navi/internal/port"
+)
+
+// commandPlan describes how a command is executed, once its configuration is resolved
+type commandPlan struct {
+	Id          string            `json:"id,omitempty"`
+	Dir         string            `json:"dir"`
+	Shell       string            `json:"shell"`
+	SharedShell bool              `json:"shared_shell,omitempty"`
+	Interactive bool              `json:"interactive,omitempty"`
+	Processes   []processPlan     `json:"processes,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	EnvSources  map[string]string `json:"env_sources,omitempty"`
+	InheritEnv  *[]string         `json:"inherit_env,omitempty"` // Inherited variables, when not all are
+	UnsetEnv    []string          `json:"unset_env,omitempty"`
+	Watch       *watchPlan        `json:"watch,omitempty"`
+	Timeout     string            `json:"timeout,omitempty"`
+	Condition   any               `json:"if,omitempty"`
+	Sources     []string          `json:"sources,omitempty"`
+	Outputs     []string          `json:"outputs,omitempty"`
+	Deps        []commandPlan     `json:"deps,omitempty"`
+	Pre         []hookPlan        `json:"pre,omitempty"`
+	Post        []hookPlan        `json:"post,omitempty"`
+	After       []hookPlan        `json:"after,omitempty"`
+}
+
+// processPlan describes a process started for one or more commands of a list
+type processPlan struct {
+	Run             []string `json:"run"`
+	Argv            []string `json:"argv" yaml:"argv,flow"`
+	ContinueOnError bool     `json:"continue_on_error,omitempty"`
+	OkExitCodes     []int    `json:"ok_exit_codes,omitempty" yaml:"ok_exit_codes,omitempty,flow"`
+}
+
+// watchPlan describes the files watched to restart a command
+type watchPlan struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// hookPlan describes a hook executed around a command
+type hookPlan struct {
+	Level       string `json:"level"`          // `project` or `command`
+	When        string `json:"when,omitempty"` // Result triggering an after hook
+	commandPlan `yaml:",inline"`
+}
+
+// runnerPlan describes how the commands of a runner are scheduled
+type runnerPlan struct {
+	Runner    string              `json:"runner"`
+	Profile   string              `json:"profile,omitempty"`
+	Serial    bool                `json:"serial,omitempty"`
+	Dependent bool                `json:"dependent,omitempty"`
+	Commands  []runnerCommandPlan `json:"commands"`
+}
+
+// runnerCommandPlan describes a command of a runner and its scheduling
+type runnerCommandPlan struct {
+	Cmd       string       `json:"cmd"`
+	Name      string       `json:"name,omitempty"`
+	WaitsFor  string       `json:"waits_for,omitempty"` // Serial command that must finish first
+	Serial    bool         `json:"serial,omitempty"`
+	Dependent bool         `json:"dependent,omitempty"`
+	Delay     float64      `json:"delay,omitempty"`
+	Awaits    *awaitsPlan  `json:"awaits,omitempty"`
+	Restart   *restartPlan `json:"restart,omitempty"`
+	Condition any          `json:"if,omitempty"`
+	Command   commandPlan  `json:"command"`
+}
+
+// awaitsPlan describes the ports a runner command waits for
+type awaitsPlan struct {
+	Ports   []int   `json:"ports" yaml:"ports,flow"`
+	Timeout float64 `json:"timeout"`
+}
+
+// restartPlan describes the restart policy of a runner command
+type restartPlan struct {
+	Condition string  `json:"condition"`
+	Retries   int     `json:"retries"` // 0 = unlimited
+	Interval  float64 `json:"interval"`
+}
+
+// runDryRunCommand prints the execution plan of a runner or command, without executing it
+func runDryRunCommand(args []string, cliRunnerFlags RunnerFlags) {
+	plan, err := buildExecutionPlan(args, cliRunnerFlags)
+	if err != nil {
+		logger.Error("%v", err)
+		os.Exit(1)
+	}
+
+	output, err := yaml.MarshalWithOptions(plan, yaml.IndentSequence(true))
+	if err != nil {
+		logger.Error("Failed to generate execution plan: %v", err)
+		os.Exit(1)
+	}
+
+	logger.Info("Dry run of `%s`. No command will be executed", strings.Join(args, " "))
+	fmt.Print(logger.MaskSecrets(string(output)))
+	os.Exit(0)
+}
+
+// buildExecutionPlan resolves the runner or command matching the arguments into its execution plan
+func buildExecutionPlan(args []string, cliRunnerFlags RunnerFlags) (any, error) {
+	if isRunnerConfigured(args) {
+		return buildRunnerPlan(args, cliRunnerFlags)
+	}
+
+	return buildCommandPlan(args)
+}
+
+// buildCommandPlan resolves a command, with its dependencies and hooks
+func buildCommandPlan(args []string) (commandPlan, error) {
+	if _, _, found, _, _ := findGlobalCommandOrProjectOrProjectCommand(args); !found {
+		return commandPlan{}, fmt.Errorf("Could not find `%s` in yaml configuration", args[0])
+	}
+
+	projectCmd, _, err := getProjectCommand(args)
+	if err != nil {
+		return commandPlan{}, err
+	}
+
+	if err := resolveCommandDependencies(projectCmd, nil); err != nil {
+		return commandPlan{}, err
+	}
+
+	return projectCmd.buildPlan(true), nil
+}
+
+// buildRunnerPlan resolves the commands of a runner and how they are scheduled
+func buildRunnerPlan(args []string, cliRunnerFlags RunnerFlags) (runnerPlan, error) {
+	commandsList, runnerName, flagStrings, _, _, err := findMatchingRunnerConfiguration(args, cliRunnerFlags, true)
+	if err != nil {
+		return runnerPlan{}, err
+	}
+
+	runnerFlags := convertStringFlagsToRunnerFlags(flagStrings)
+	runnerExecutions, err := prepareRunnerExecutions(commandsList, runnerName, runnerFlags)
+	if err != nil {
+		return runnerPlan{}, err
+	}
+
+	plan := runnerPlan{
+		Runner:    runnerName,
+		Profile:   activeProfile,
+		Serial:    runnerFlags.Serial,
+		Dependent: runnerFlags.Dependent,
+		Commands:  []runnerCommandPlan{},
+	}
+
+	// Each command starts once the last serial command before it has finished
+	lastSerialCmd := ""
+
+	for _, execution := range runnerExecutions {
+		runnerCmd := execution.runnerCmd
+		commandPlan := runnerCommandPlan{
+			Cmd:       execution.runnerCmdStr,
+			Name:      runnerCmd.Name,
+			WaitsFor:  lastSerialCmd,
+			Serial:    runnerCmd.Serial,
+			Dependent: runnerCmd.Dependent,
+			Delay:     runnerCmd.Delay,
+			Condition: runnerCmd.Condition,
+			Command:   execution.projectCmd.buildPlan(true),
+		}
+
+		if runnerCmd.Awaits != nil {
+			ports, timeout, err := portUtils.ParsePortConfiguration(runnerCmd.Awaits)
+			if err != nil {
+				return runnerPlan{}, err
+			}
+			commandPlan.Awaits = &awaitsPlan{Ports: ports, Timeout: timeout}
+		}
+
+		if execution.enableRestart {
+			commandPlan.Restart = &restartPlan{
+				Condition: execution.restartCondition,
+				Retries:   execution.maxRetries,
+				Interval:  execution.retryInterval,
+			}
+		}
+
+		if runnerCmd.Serial {
+			lastSerialCmd = execution.runnerCmdStr
+		}
+
+		plan.Commands = append(plan.Commands, commandPlan)
+	}
+
+	return plan, nil
+}
+
+// buildPlan describes the execution of the command, its dependencies and its hooks.
+// Hooks have no after hooks of their own, since only the main command runs them
+func (cmd *ProjectCommand) buildPlan(isMainCommand bool) commandPlan {
+	plan := commandPlan{
+		Id:          cmd.Identifier,
+		Dir:         cmd.Dir,
+		Shell:       cmd.getShell(),
+		SharedShell: cmd.SharedShell,
+		Interactive: cmd.Interactive,
+		Condition:   cmd.Condition,
+		Sources:     cmd.Sources,
+		Outputs:     cmd.Outputs,
+	}
+
+	plan.Env, plan.EnvSources = cmd.getPlanEnv()
+	plan.InheritEnv = cmd.EnvFilter.getPlanInheritEnv()
+	plan.UnsetEnv = cmd.EnvFilter.Unset
+
+	// The arguments are the ones the executor uses, so commands cmd can't split have none
+	for _, group := range cmd.getStepGroups() {
+		argv, _, _ := cmd.getProcessArgs(group.steps)
+		plan.Processes = append(plan.Processes, processPlan{
+			Run:             group.steps,
+			Argv:            argv,
+			ContinueOnError: group.policy.ContinueOnError,
+			OkExitCodes:     group.policy.OkExitCodes,
+		})
+	}
+
+	if hasFilesToWatch(cmd.WatchPatterns) {
+		plan.Watch = &watchPlan{Include: cmd.WatchPatterns.Include, Exclude: cmd.WatchPatterns.Exclude}
+	}
+
+	if cmd.Timeout > 0 {
+		plan.Timeout = formatTimeout(cmd.Timeout)
+	}
+
+	for _, depCmd := range cmd.Dependencies {
+		plan.Deps = append(plan.Deps, depCmd.buildPlan(true))
+	}
+
+	// Hooks are listed in their execution order
+	plan.Pre = appendHookPlan(plan.Pre, cmd.ProjPreCommand, "project", "")
+	plan.Pre = appendHookPlan(plan.Pre, cmd.PreCommand, "command", "")
+	plan.Post = appendHookPlan(plan.Post, cmd.PostCommand, "command", "")
+	plan.Post = appendHookPlan(plan.Post, cmd.ProjPostCommand, "project", "")
+
+	if isMainCommand {
+		plan.After = appendAfterHookPlans(plan.After, cmd.AfterCommand, "command")
+		plan.After = appendAfterHookPlans(plan.After, cmd.ProjAfterCommand, "project")
+	}
+
+	return plan
+}
+
+// getPlanEnv returns the environment variables set for the command on top of the environment
+// of navi, and the source of each one
+func (cmd *ProjectCommand) getPlanEnv() (map[string]string, map[string]string) {
+	env := make(map[string]string)
+	envSources := make(map[string]string)
+
+	// Later variables override earlier ones, as when the process is started
+	for _, envVar := range cmd.EnvFilter.apply(nil, append(slices.Clone(cmd.EnvVars), forceColorEnvVar)) {
+		if key, value, found := strings.Cut(envVar, "="); found {
+			env[key] = logger.MaskSecrets(cmd.EnvCommands.describe(value))
+			envSources[key] = cmd.EnvSources[key]
+		}
+	}
+
+	if forceColorName := strings.Split(forceColorEnvVar, "=")[0]; !cmd.EnvFilter.isUnset(forceColorName) {
+		envSources[forceColorName] = "navi"
+	}
+	return env, envSources
+}
+
+// appendHookPlan adds the plan of a hook, if defined
+func appendHookPlan(plans []hookPlan, hookCmd *ProjectCommand, level, when string) []hookPlan {
+	if hookCmd == nil {
+		return plans
+	}
+
+	return append(plans, hookPlan{Level: level, When: when, commandPlan: hookCmd.buildPlan(false)})
+}
+
+// appendAfterHookPlans adds the plans of an after hook, split by the results triggering them
+func appendAfterHookPlans(plans []hookPlan, afterCmd *ProjectCommand, level string) []hookPlan {
+	if afterCmd == nil {
+		return plans
+	}
+
+	hasResultHooks := afterCmd.AfterSuccessCommand != nil || afterCmd.AfterFailureCommand != nil ||
+		afterCmd.AfterChangeCommand != nil || afterCmd.AfterAlwaysCommand != nil
+
+	if !hasResultHooks {
+		return appendHookPlan(plans, afterCmd, level, "")
+	}
+
+	plans = appendHookPlan(plans, afterCmd.AfterSuccessCommand, level, "success")
+	plans = appendHookPlan(plans, afterCmd.AfterFailureCommand, level, "failure")
+	plans = appendHookPlan(plans, afterCmd.AfterChangeCommand, level, "change")
+	return appendHookPlan(plans, afterCmd.AfterAlwaysCommand, level, "always")
+}
@@ -29,17 +29,23 @@ func executeRunner(contextCmd Ctx, commandArgs []string, commandLineRunnerFlags
 
 	parsedRunnerFlags := convertStringFlagsToRunnerFlags(runnerFlagStrings)
 
+	// Mention the active profile, since it changes the executed commands
+	profileSuffix := ""
+	if activeProfile != "" {
+		profileSuffix = fmt.Sprintf(" using profile `%s`", activeProfile)
+	}
+
 	if len(runnerFlagStrings) > 0 {
 		if isInlineRunner {
-			logger.Info("Starting inline runner with flags [%s] and %d command(s)", strings.Join(runnerFlagStrings, ", "), len(runnerCommandsList))
+			logger.Info("Starting inline runner with flags [%s] and %d command(s)%s", strings.Join(runnerFlagStrings, ", "), len(runnerCommandsList), profileSuffix)
 		} else {
-			logger.Info("Starting runner `%s` with flags [%s]", runnerName, strings.Join(runnerFlagStrings, ", "))
+			logger.Info("Starting runner `%s` with flags [%s]%s", runnerName, strings.Join(runnerFlagStrings, ", "), profileSuffix)
 		}
 	} else {
 		if isInlineRunner {
-			logger.Info("Starting inline runner with %d command(s)", len(runnerCommandsList))
+			logger.Info("Starting inline runner with %d command(s)%s", len(runnerCommandsList), profileSuffix)
 		} else {
-			logger.Info("Starting runner `%s`", runnerName)
+			logger.Info("Starting runner `%s`%s", runnerName, profileSuffix)
 		}
 	}
 
@@ -144,7 +150,9 @@ func executeRunnerCommands(contextCmd Ctx, runnerName string, commandsList []map
 
 	// Define handlers for command failure/completion
 	handlers := CommandHandlers{
-		serialFailure: func(cmdConfig RunnerCommand) {
+		commandFailure: func(cmdConfig RunnerCommand, err error) {
+			recordFailure(err)
+
 			if cmdConfig.Serial || runnerFlags.Serial {
 				logger.Error("A serial command in runner `%s` has failed", runnerName)
 				gracefulShutdown(contextCmd, "")
@@ -184,6 +192,16 @@ func executeRunnerCommands(contextCmd Ctx, runnerName string, commandsList []map
 func prepareRunnerExecutions(commandsList []map[string]any, runnerName string, runnerFlags RunnerFlags) ([]RunnerExecution, error) {
 	runnerExecutions := []RunnerExecution{}
 
+	yamlConfig, _, err := getYamlConfiguration(true)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load configuration from YAML file: %v", err)
+	}
+
+	scope, err := newTemplateScope("", "").withVars(yamlConfig.Vars)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, command := range commandsList {
 		// Extract command string
 		commandString, ok := command["cmd"].(string)
@@ -191,6 +209,11 @@ func prepareRunnerExecutions(commandsList []map[string]any, runnerName string, r
 			return nil, fmt.Errorf("Runner command in runner `%s` must have a `cmd` key", runnerName)
 		}
 
+		// Render template expressions with the global variables
+		if commandString, err = scope.renderTemplate(commandString); err != nil {
+			return nil, err
+		}
+
 		var runnerCmd RunnerCommand
 		runnerCmd.Cmd = commandString
 
@@ -207,12 +230,29 @@ func prepareRunnerExecutions(commandsList []map[string]any, runnerName string, r
 			runnerCmd.Delay = delaySeconds
 		}
 
+		// Parse execution condition
+		if condition, ok := command["if"]; ok {
+			runnerCmd.Condition = condition
+		}
+
+		// Parse execution timeout
+		if timeoutRaw, ok := command["timeout"]; ok {
+			timeout, isValid := parseTimeout(timeoutRaw)
+			if !isValid {
+				return nil, fmt.Errorf(
+					"Invalid value for parameter `timeout` in runner `%s`. Must be a duration like `30s` or `5m`, or a number of seconds",
+					runnerName,
+				)
+			}
+			runnerCmd.Timeout = timeout
+		}
+
 		// Parse restart configuration
 		enableRestart, maxRetries, restartCondition, retryInterval := parseRestartConfig(command)
 		runnerCmd.Restart = enableRestart
 
 		// Validate restart condition if restart is enabled
-		if enableRestart && restartCondition != "always" && restartCondition != "failure" && restartCondition != "success" {
+		if enableRestart && !utils.SliceContainsValue(restartConditions, restartCondition) {
 			return nil, fmt.Errorf(
 				"Invalid value for parameter `condition` in runner `%s`. Must be `always`, `failure`, or `success`",
 				runnerName,
@@ -232,15 +272,28 @@ func prepareRunnerExecutions(commandsList []map[string]any, runnerName string, r
 
 		projectCmd, notFound, err := getProjectCommand(commandTokens)
 		if err != nil {
-			if notFound {
-				projectCmd = createFallbackProjectCommand(runnerName, runnerCmd.Cmd, commandTokens)
-			} else {
+			if !notFound {
 				return nil, err
 			}
+
+			if projectCmd, err = createFallbackProjectCommand(yamlConfig, runnerName, runnerCmd.Cmd, commandTokens); err != nil {
+				return nil, err
+			}
+		} else if err := resolveCommandDependencies(projectCmd, nil); err != nil {
+			return nil, err
+		} else {
+			// Entries without arguments, restarts or watch mode run once with the dependencies
+			projectCmd.SharedExecution = len(commandTokens) == 1 && !enableRestart &&
+				len(projectCmd.WatchPatterns.Include) == 0
 		}
 
 		setupCommandLogPrefix(projectCmd, projectCmd.Identifier, runnerCmd.Name)
 
+		// The timeout of the runner entry replaces the one of the command
+		if runnerCmd.Timeout > 0 {
+			projectCmd.Timeout = runnerCmd.Timeout
+		}
+
 		runnerExecutions = append(runnerExecutions, RunnerExecution{
 			projectCmd:       projectCmd,
 			runnerCmd:        &runnerCmd,
@@ -252,9 +305,34 @@ func prepareRunnerExecutions(commandsList []map[string]any, runnerName string, r
 		})
 	}
 
+	if err := checkParallelInteractiveCommands(runnerExecutions, runnerName); err != nil {
+		return nil, err
+	}
+
 	return runnerExecutions, nil
 }
 
+// checkParallelInteractiveCommands rejects interactive commands sharing the terminal,
+// which happens when an interactive command is followed by another one without being serial
+func checkParallelInteractiveCommands(runnerExecutions []RunnerExecution, runnerName string) error {
+	for i, execution := range runnerExecutions {
+		if !execution.projectCmd.Interactive || execution.runnerCmd.Serial {
+			continue
+		}
+
+		for _, nextExecution := range runnerExecutions[i+1:] {
+			if nextExecution.projectCmd.Interactive {
+				return fmt.Errorf(
+					"Interactive commands `%s` and `%s` in runner `%s` would run in parallel. Make them `serial` to share the terminal",
+					execution.runnerCmdStr, nextExecution.runnerCmdStr, runnerName,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
 // parseCommandFlags extracts serial and dependent flags from command config
 func parseCommandFlags(runnerCmd *RunnerCommand, commandConfig map[string]any, runnerFlags RunnerFlags) {
 	// Parse serial execution flag
@@ -311,13 +389,20 @@ func parseRestartConfig(commandConfig map[string]any) (bool, int, string, float6
 }
 
 // createFallbackProjectCommand creates a generic project command for non-project commands
-func createFallbackProjectCommand(runnerName, commandStr string, tokens []string) *ProjectCommand {
+func createFallbackProjectCommand(yamlConfig YamlConfig, runnerName, commandStr string, tokens []string) (*ProjectCommand, error) {
+	// The variables inherited and removed by the configuration apply to raw commands too
+	envFilter, isValid := (EnvFilter{}).withSettings(yamlConfig.InheritEnv, yamlConfig.UnsetEnv)
+	if !isValid {
+		return nil, fmt.Errorf("The top-level `inherit_env` and `unset_env` fields must be a boolean or a list of variable names")
+	}
+
 	return &ProjectCommand{
 		Identifier:  runnerName,
 		Dir:         applicationRootPath,
 		EnvVars:     []string{},
+		EnvFilter:   envFilter,
 		CommandList: []string{commandStr},
-	}
+	}, nil
 }
 
 // setupCommandLogPrefix configures unique log prefix for a command
@@ -353,7 +438,18 @@ func launchRunnerCommand(
 			close(nextStartSignal) // Allow next command to start immediately
 		}
 
-		if execution.enableRestart {
+		skipped, err := projectCmd.skipIfConditionNotMet(contextCmd, cmdConfig.Condition)
+
+		switch {
+		case err != nil:
+			logger.ErrorWithPrefix(projectCmd.GetLogPrefix(), "%v", err)
+			handlers.commandFailure(cmdConfig, err)
+			handlers.dependentCompletion(cmdConfig)
+
+		case skipped:
+			// Skipped commands count as successful, so the next serial commands start
+
+		case execution.enableRestart:
 			executeRestartableCommand(
 				contextCmd,
 				projectCmd,
@@ -363,7 +459,8 @@ func launchRunnerCommand(
 				execution.restartCondition,
 				handlers,
 			)
-		} else {
+
+		default:
 			executeOneTimeCommand(
 				contextCmd,
 				projectCmd,
@@ -409,7 +506,7 @@ func executeRestartableCommand(
 			)
 
 			if !shouldContinue {
-				handlers.serialFailure(cmdConfig)
+				handlers.commandFailure(cmdConfig, err)
 				handlers.dependentCompletion(cmdConfig)
 				break
 			}
@@ -437,7 +534,7 @@ func executeRestartableCommand(
 				}
 			}
 
-			handlers.serialFailure(cmdConfig)
+			handlers.commandFailure(cmdConfig, err)
 		} else if shouldRestartOnCondition("success", restartCondition) {
 			if scheduleRetry(projectCmd.GetLogPrefix, maxRetries, retryDelay, &retryCount) {
 				continue
@@ -472,7 +569,7 @@ func executeOneTimeCommand(
 			logger.ErrorWithPrefix(projectCmd.GetLogPrefix(), "%v", err)
 		}
 
-		handlers.serialFailure(cmdConfig)
+		handlers.commandFailure(cmdConfig, err)
 		handlers.dependentCompletion(cmdConfig)
 
 		if cmdConfig.Serial && !process.TerminatingProcesses {
@@ -488,11 +585,11 @@ func executeOneTimeCommand(
 	}
 
 	// Execute command
-	err := executeCommandWithAfterHandling(contextCmd, projectCmd)
+	err := runRunnerEntryOnce(contextCmd, projectCmd)
 
 	// Handle execution result
 	if err != nil && !errors.Is(err, ErrProcessTerminated) {
-		handlers.serialFailure(cmdConfig)
+		handlers.commandFailure(cmdConfig, err)
 		handlers.dependentCompletion(cmdConfig)
 	}
 }
@@ -527,8 +624,30 @@ func handlePortWaitError(
 
 // executeCommandWithAfterHandling runs a command and its 'after' commands
 func executeCommandWithAfterHandling(contextCmd Ctx, projectCmd *ProjectCommand) error {
+	return runCommandWithAfterHandling(contextCmd, projectCmd, true)
+}
+
+// runCommandWithAfterHandling runs a command and its 'after' commands. Failures of dependencies
+// are returned without being logged, so the command requiring them logs them once
+func runCommandWithAfterHandling(contextCmd Ctx, projectCmd *ProjectCommand, logFailure bool) error {
+	// Execute the commands required by `deps` first
+	if err := runCommandDependencies(contextCmd, projectCmd); err != nil {
+		if logFailure && !errors.Is(err, ErrProcessTerminated) {
+			logger.ErrorWithPrefix(projectCmd.GetLogPrefix(), "%v", err)
+		}
+		return err
+	}
+
+	// Dynamic environment variables are evaluated once the command starts
+	if err := projectCmd.resolveDynamicEnv(); err != nil {
+		if logFailure {
+			logger.ErrorWithPrefix(projectCmd.GetLogPrefix(), "%v", err)
+		}
+		return err
+	}
+
 	err := projectCmd.execute(contextCmd)
-	if err != nil && !errors.Is(err, ErrProcessTerminated) {
+	if err != nil && logFailure && !errors.Is(err, ErrProcessTerminated) {
 		logger.ErrorWithPrefix(projectCmd.GetLogPrefix(), "%v", err)
 	}
 
@@ -537,6 +656,7 @@ func executeCommandWithAfterHandling(contextCmd Ctx, projectCmd *ProjectCommand)
 		afterErr := projectCmd.executeAfterHooks(err, contextCmd, false)
 		if afterErr != nil {
 			logger.ErrorWithPrefix(projectCmd.GetLogPrefix(), "Fail during execution of after command(s): %v", afterErr)
+			recordFailure(afterErr)
 			gracefulShutdown(contextCmd, "")
 		}
 	}
@@ -0,0 +1,339 @@
+package navi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-navi/navi/internal/logger"
+)
+
+// JSON Schema dialect of the generated schema
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// runSchemaCommand prints the JSON schema of the configuration file and exits
+func runSchemaCommand() {
+	schema, err := json.MarshalIndent(buildConfigSchema(), "", "  ")
+	if err != nil {
+		logger.Error("Failed to generate JSON schema: %v", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(string(schema))
+	os.Exit(0)
+}
+
+// buildConfigSchema generates the JSON schema of the configuration file from the accepted keys
+func buildConfigSchema() map[string]any {
+	schema := buildObjectSchema(topLevelKeys)
+	schema["$schema"] = jsonSchemaDialect
+	schema["title"] = "Navi configuration"
+
+	// Commands extending another one may inherit `run`
+	detailedCommand := buildObjectSchema(commandKeys)
+	delete(detailedCommand, "required")
+	detailedCommand["anyOf"] = []any{
+		map[string]any{"required": []string{"run"}},
+		map[string]any{"required": []string{"extends"}},
+	}
+
+	schema["$defs"] = map[string]any{
+		"stringList": map[string]any{
+			"anyOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			},
+		},
+		"dotenvFile": map[string]any{
+			"type":        "string",
+			"description": "Path of a dotenv, JSON, YAML or TOML file, optionally followed by `| KEY1, KEY2` to load only some keys, or `| * secret` to mask the values",
+			"pattern":     `^[^|]+(\|[^|]+)?$`,
+		},
+		"detailedDotenvFile": buildObjectSchema(dotenvFileKeys),
+		"dotenv": map[string]any{
+			"anyOf": []any{
+				map[string]any{"$ref": "#/$defs/dotenvFile"},
+				map[string]any{"$ref": "#/$defs/detailedDotenvFile"},
+				map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"anyOf": []any{
+							map[string]any{"$ref": "#/$defs/dotenvFile"},
+							map[string]any{"$ref": "#/$defs/detailedDotenvFile"},
+						},
+					},
+				},
+			},
+		},
+		"env": map[string]any{
+			"type": "object",
+			"additionalProperties": map[string]any{
+				"anyOf": []any{
+					map[string]any{"type": []string{"string", "number", "boolean", "null"}},
+					buildObjectSchema(envVarKeys),
+				},
+			},
+		},
+		"vars": map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"type": []string{"string", "number", "boolean"}},
+		},
+		"ports": map[string]any{
+			"anyOf": []any{
+				map[string]any{"type": "integer"},
+				map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+			},
+		},
+		"detailedCommand": detailedCommand,
+		"command": map[string]any{
+			"anyOf": []any{
+				map[string]any{"$ref": "#/$defs/stringList"},
+				map[string]any{"$ref": "#/$defs/detailedCommand"},
+			},
+		},
+		"commandOverride": map[string]any{
+			"anyOf": []any{
+				map[string]any{"$ref": "#/$defs/stringList"},
+				buildObjectSchema(getOverrideConfigKeys(commandKeys)),
+			},
+		},
+		"project":         buildObjectSchema(projectKeys),
+		"template":        buildObjectSchema(templateKeys),
+		"projectOverride": buildObjectSchema(getOverrideConfigKeys(projectKeys)),
+		"profile":         buildObjectSchema(profileKeys),
+		"runnerCommand":   buildObjectSchema(runnerCommandKeys),
+		"condition": map[string]any{
+			"anyOf": []any{
+				buildObjectSchema(conditionKeys),
+				map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/condition"}},
+			},
+		},
+		"runner": map[string]any{
+			"anyOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{
+					"type":     "array",
+					"minItems": 1,
+					"items": map[string]any{
+						"anyOf": []any{
+							map[string]any{"type": "string"},
+							map[string]any{"$ref": "#/$defs/runnerCommand"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return schema
+}
+
+// buildObjectSchema generates the schema of a settings map from its accepted keys
+func buildObjectSchema(keys []configKey) map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+
+	for _, key := range keys {
+		property := buildValueSchema(key)
+		if key.description != "" {
+			property["description"] = key.description
+		}
+
+		properties[key.name] = property
+		if key.required {
+			required = append(required, key.name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// buildValueSchema generates the schema of a value from its kind
+func buildValueSchema(key configKey) map[string]any {
+	switch key.kind {
+	case stringValue:
+		if len(key.allowed) > 0 {
+			return map[string]any{"type": "string", "enum": key.allowed}
+		}
+		return map[string]any{"type": "string"}
+
+	case numberValue:
+		return map[string]any{"type": "number"}
+
+	case integerValue:
+		return map[string]any{"type": "integer"}
+
+	case durationValue:
+		return map[string]any{
+			"anyOf": []any{
+				map[string]any{"type": "number", "exclusiveMinimum": 0},
+				map[string]any{"type": "string", "pattern": `^(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+$`},
+			},
+		}
+
+	case boolValue:
+		return map[string]any{"type": "boolean"}
+
+	case scalarValue:
+		return map[string]any{"type": []string{"string", "number", "boolean"}}
+
+	case stringListValue:
+		return map[string]any{"$ref": "#/$defs/stringList"}
+
+	case runValue:
+		return map[string]any{
+			"anyOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"anyOf": []any{
+							map[string]any{"type": "string"},
+							buildObjectSchema(getNestedConfigKeys(key.kind)),
+						},
+					},
+				},
+			},
+		}
+
+	case exitCodesValue:
+		return map[string]any{
+			"anyOf": []any{
+				map[string]any{"type": "integer"},
+				map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+			},
+		}
+
+	case dotenvValue:
+		return map[string]any{"$ref": "#/$defs/dotenv"}
+
+	case envValue:
+		return map[string]any{"$ref": "#/$defs/env"}
+
+	case inheritEnvValue:
+		return map[string]any{
+			"anyOf": []any{
+				map[string]any{"type": "boolean"},
+				map[string]any{"$ref": "#/$defs/stringList"},
+			},
+		}
+
+	case varsValue:
+		return map[string]any{"$ref": "#/$defs/vars"}
+
+	case paramsValue:
+		return map[string]any{"type": "array", "items": buildObjectSchema(paramKeys)}
+
+	case watchValue:
+		return map[string]any{
+			"anyOf": []any{
+				map[string]any{"$ref": "#/$defs/stringList"},
+				buildObjectSchema(getNestedConfigKeys(key.kind)),
+			},
+		}
+
+	case commandValue:
+		return map[string]any{"$ref": "#/$defs/command"}
+
+	case afterCommandValue:
+		return map[string]any{
+			"anyOf": []any{
+				map[string]any{"$ref": "#/$defs/command"},
+				buildObjectSchema(getNestedConfigKeys(key.kind)),
+			},
+		}
+
+	case commandMapValue:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"$ref": "#/$defs/command"},
+		}
+
+	case projectMapValue:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"$ref": "#/$defs/project"},
+		}
+
+	case commandOverrideMapValue:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"$ref": "#/$defs/commandOverride"},
+		}
+
+	case projectOverrideMapValue:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"$ref": "#/$defs/projectOverride"},
+		}
+
+	case templateMapValue:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"$ref": "#/$defs/template"},
+		}
+
+	case profileMapValue:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"$ref": "#/$defs/profile"},
+		}
+
+	case runnerMapValue:
+		return map[string]any{
+			"type":                 "object",
+			"propertyNames":        map[string]any{"pattern": getRunnerNamePattern()},
+			"additionalProperties": map[string]any{"$ref": "#/$defs/runner"},
+		}
+
+	case restartValue:
+		return map[string]any{
+			"anyOf": []any{
+				map[string]any{"type": "boolean"},
+				buildObjectSchema(getNestedConfigKeys(key.kind)),
+			},
+		}
+
+	case awaitsValue:
+		return map[string]any{
+			"anyOf": []any{
+				map[string]any{"$ref": "#/$defs/ports"},
+				buildObjectSchema(getNestedConfigKeys(key.kind)),
+			},
+		}
+
+	case portsValue:
+		return map[string]any{"$ref": "#/$defs/ports"}
+
+	case conditionValue:
+		return map[string]any{"$ref": "#/$defs/condition"}
+
+	case conditionListValue:
+		return map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/condition"}}
+	}
+
+	return map[string]any{}
+}
+
+// getRunnerNamePattern returns the pattern of runner names with optional `[flag1,flag2]` suffix
+func getRunnerNamePattern() string {
+	quotedFlags := make([]string, len(runnerFlagNames))
+	for i, flagName := range runnerFlagNames {
+		quotedFlags[i] = regexp.QuoteMeta(flagName)
+	}
+
+	flagPattern := `\s*(` + strings.Join(quotedFlags, "|") + `)\s*`
+	return `^[^\[\]]+(\[(` + flagPattern + `(,` + flagPattern + `)*)?\])?$`
+}
@@ -4,28 +4,56 @@ import (
 	"context"
 	"os/exec"
 	"sync"
+	"time"
 
 	"github.com/go-navi/navi/internal/watcher"
 )
 
 // YamlConfig represents the top-level navi.yml structure
 type YamlConfig struct {
-	Projects map[string]ProjectConfig // Project definitions
-	Runners  map[string]any           // Runner definitions
-	Commands map[string]any           // Command definitions
+	Include   any                      // Additional configuration files
+	Vars      map[string]any           // Variables available to templates
+	Projects  map[string]ProjectConfig // Project definitions
+	Runners   map[string]any           // Runner definitions
+	Commands  map[string]any           // Command definitions
+	Profiles  map[string]ProfileConfig // Overrides selected with `--profile`
+	Templates map[string]ProjectConfig // Shared project settings referenced with `template`
+	Secrets   []string                 // Name patterns of the environment variables masked in the output
+
+	InheritEnv any `yaml:"inherit_env"` // Variables inherited from the environment of navi: `true`, `false` or name patterns
+	UnsetEnv   any `yaml:"unset_env"`   // Name patterns of the variables removed from the environment of the commands
+
+	commandDirs     map[string]string // Directory of the file defining each command
+	definitionFiles map[string]string // File defining each variable, command, project and runner
+	overlayValues   map[string]string // Path of each value set by an overlay file => overlay file
 }
 
 // ProjectConfig defines a project's settings in the YAML file
 type ProjectConfig struct {
-	Dir    string            // Project directory
-	Cmds   map[string]any    // Available commands
-	Pre    any               // Commands before main execution
-	Post   any               // Commands after main execution
-	After  any               // Commands after completion
-	Dotenv any               // Environment file settings
-	Watch  any               // Files to watch for changes
-	Env    map[string]string // Environment variables
-	Shell  string            // Shell for execution
+	Dir      string         // Project directory
+	Cmds     map[string]any // Available commands
+	Pre      any            // Commands before main execution
+	Post     any            // Commands after main execution
+	After    any            // Commands after completion
+	Dotenv   any            // Environment file settings
+	Watch    any            // Files to watch for changes
+	Env      map[string]any // Environment variables, as values or maps with `value` and `secret`
+	Shell    string         // Shell for execution
+	Vars     map[string]any // Variables available to templates
+	Template string         // Template providing shared settings
+
+	InheritEnv any `yaml:"inherit_env"` // Variables inherited from the environment of navi
+	UnsetEnv   any `yaml:"unset_env"`   // Variables removed from the environment of the commands
+
+	baseDir string // Directory of the file defining the project
+}
+
+// ProfileConfig defines overrides deep-merged onto the configuration
+type ProfileConfig struct {
+	Vars     map[string]any            // Variables to add or override
+	Commands map[string]any            // Commands to add or override
+	Projects map[string]map[string]any // Project settings to add or override
+	Runners  map[string]any            // Runners to add or replace
 }
 
 // RunnerFlags controls command execution flow behavior
@@ -36,13 +64,15 @@ type RunnerFlags struct {
 
 // RunnerCommand defines command execution parameters
 type RunnerCommand struct {
-	Cmd       string  // Command to execute
-	Name      string  // Display name
-	Delay     float64 // Pre-execution delay in seconds
-	Restart   any     // Restart settings
-	Awaits    any     // Ports to wait for
-	Serial    bool    // Block subsequent commands
-	Dependent bool    // Stop all on failure
+	Cmd       string        // Command to execute
+	Name      string        // Display name
+	Delay     float64       // Pre-execution delay in seconds
+	Timeout   time.Duration // Maximum execution time (0 = command setting)
+	Condition any           // `if` condition deciding whether the command runs
+	Restart   any           // Restart settings
+	Awaits    any           // Ports to wait for
+	Serial    bool          // Block subsequent commands
+	Dependent bool          // Stop all on failure
 }
 
 // RunnerExecution manages command execution state
@@ -62,6 +92,11 @@ type ProjectCommand struct {
 	Dir                 string               // Working directory
 	CommandList         []string             // Raw commands arguments
 	EnvVars             []string             // Environment variables
+	EnvSources          map[string]string    // Source of the value of each environment variable
+	InheritEnv          any                  // `inherit_env` of the command (nil = the one of its parent)
+	UnsetEnv            []string             // `unset_env` of the command, added to the ones of its parent
+	EnvFilter           EnvFilter            // Variables inherited and removed, once the parent settings are applied
+	EnvCommands         *envCommandRegistry  // Commands of the dynamic environment variables of the command and its hooks
 	ProjPreCommand      *ProjectCommand      // Project pre-hook
 	PreCommand          *ProjectCommand      // Command pre-hook
 	PostCommand         *ProjectCommand      // Command post-hook
@@ -79,6 +114,25 @@ type ProjectCommand struct {
 	LogPrefix           string               // Log prefix text
 	LogPrefixId         string               // Log prefix ID
 	LogPrefixColor      string               // Log prefix color
+	Timeout             time.Duration        // Maximum execution time (0 = none)
+	Condition           any                  // `if` condition deciding whether the command runs
+	Interactive         bool                 // Whether the command is attached to the terminal
+	SharedShell         bool                 // Whether the commands of the list run in a single shell
+	StepPolicies        []StepPolicy         // Failure policy of each command of the list (nil = stop on failure)
+	ToleratedFailures   bool                 // Whether the last execution tolerated a failed step
+	Skipped             bool                 // Whether the last execution was skipped by its condition or cache
+	UpToDate            bool                 // Whether the sources and outputs are unchanged since the last execution
+	Sources             []string             // File patterns whose content decides if the command is up to date
+	Outputs             []string             // File patterns that must exist to skip an up-to-date command
+	DependsOn           []string             // Identifiers of the commands required by `deps`
+	Dependencies        []*ProjectCommand    // Commands executed once before this one
+	SharedExecution     bool                 // Whether a runner entry shares its execution with the dependencies
+}
+
+// commandBuild holds what the commands built from a loaded configuration share
+type commandBuild struct {
+	yamlConfig  YamlConfig          // Loaded configuration, used to resolve `extends`
+	envCommands *envCommandRegistry // Commands of the dynamic environment variables
 }
 
 // CommandConfig is an intermediate representation during command building
@@ -90,8 +144,47 @@ type CommandConfig struct {
 	After         any                  // After-command hooks
 	Dotenv        any                  // Environment files
 	WatchPatterns watcher.FilePatterns // Watch patterns
-	Env           map[string]string    // Environment variables
+	Env           map[string]any       // Environment variables, evaluated once the directory and shell are known
+	InheritEnv    any                  // Variables inherited from the environment of navi
+	UnsetEnv      []string             // Variables removed from the environment
 	Shell         string               // Shell for execution
+	Timeout       time.Duration        // Maximum execution time
+	Condition     any                  // `if` condition
+	Interactive   bool                 // Terminal passthrough
+	SharedShell   bool                 // Single shell for all commands
+	StepPolicies  []StepPolicy         // Failure policy of each command
+	Sources       []string             // Source file patterns
+	Outputs       []string             // Output file patterns
+}
+
+// StepPolicy defines how the failure of a `run` step is handled
+type StepPolicy struct {
+	ContinueOnError bool  // Any failure is tolerated
+	OkExitCodes     []int // Exit codes accepted besides 0
+}
+
+// stepGroup is a set of commands of a list executed by a single process
+type stepGroup struct {
+	steps  []string   // Commands executed by the process
+	policy StepPolicy // Failure policy, only set for commands executed alone
+}
+
+// CommandParam is a parameter declared by a command
+type CommandParam struct {
+	Name        string   // Parameter name, used as `--name=value`
+	Description string   // Description shown in the CLI
+	Default     string   // Value used when none is given
+	HasDefault  bool     // Whether a default value is defined
+	Required    bool     // Whether a value must be given
+	Allowed     []string // Accepted values (empty = any)
+}
+
+// EnvFilter defines the variables of the environment of navi given to a command, and the ones removed.
+// The zero value inherits all variables
+type EnvFilter struct {
+	Restricted bool     // Whether only the variables matching `Inherit` are inherited
+	Inherit    []string // Name patterns of the inherited variables, when restricted
+	Unset      []string // Name patterns of the removed variables, including the ones set by navi
 }
 
 // DotEnvConfig defines environment file loading configuration
@@ -102,8 +195,11 @@ type DotEnvConfig struct {
 
 // DotEnvFile specifies an environment file with optional key filtering
 type DotEnvFile struct {
-	Path string   // Path to the .env file
-	Keys []string // Specific keys to load (empty = all)
+	Path      string   // Path to the .env, JSON, YAML or TOML file
+	Keys      []string // Specific keys to load (empty = all)
+	Secret    bool     // Whether the loaded values are masked in the output
+	Format    string   // Format of the file (empty = detected from the extension)
+	Separator string   // Separator joining nested keys (empty = `__`)
 }
 
 // Ctx wraps a context with its cancel function
@@ -121,6 +217,6 @@ type ExecuteWatchData struct {
 
 // CommandHandlers contains functions to handle different command events
 type CommandHandlers struct {
-	serialFailure       func(RunnerCommand)
+	commandFailure      func(RunnerCommand, error)
 	dependentCompletion func(RunnerCommand)
 }
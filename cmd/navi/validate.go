@@ -0,0 +1,1089 @@
+package navi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+	"github.com/kballard/go-shellquote"
+
+	"github.com/go-navi/navi/internal/logger"
+	"github.com/go-navi/navi/internal/utils"
+)
+
+// configDiagnostic describes a problem found in a configuration file
+type configDiagnostic struct {
+	file    string
+	line    int
+	column  int
+	message string
+	warning bool // Whether the problem doesn't prevent the configuration from being used
+}
+
+// String formats the diagnostic as `file:line:column: message`
+func (diag configDiagnostic) String() string {
+	if diag.warning {
+		return fmt.Sprintf("%s:%d:%d: warning: %s", getDisplayPath(diag.file), diag.line, diag.column, diag.message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", getDisplayPath(diag.file), diag.line, diag.column, diag.message)
+}
+
+// runnerReference is a command referenced by a runner, checked once all files are read
+type runnerReference struct {
+	file   string
+	token  *token.Token
+	runner string
+	cmd    string
+}
+
+// commandReference is a command referenced by `extends` or `deps`, checked once all files are read
+type commandReference struct {
+	file    string
+	token   *token.Token
+	path    string // Path of the referencing command
+	project string // Project of the referencing command (empty for global commands)
+	target  string
+	usage   string // How the command is referenced, like `extended`
+}
+
+// templateReference is a template used by a project, checked once all files are read
+type templateReference struct {
+	file     string
+	token    *token.Token
+	project  string
+	template string
+}
+
+// configValidator walks configuration files and collects diagnostics
+type configValidator struct {
+	diagnostics  []configDiagnostic
+	files        []string                   // Checked files, in visiting order
+	file         string                     // File being checked
+	definitions  map[string]string          // `section.name` => `file:line` of the definition
+	globalCmds   map[string]bool            // Defined global commands
+	projectCmds  map[string]map[string]bool // Defined commands by project
+	runnerRefs   []runnerReference          // Commands referenced by runners
+	commandRefs  []commandReference         // Commands referenced by `extends` and `deps`
+	templateCmds map[string]map[string]bool // Defined commands by template
+	templateRefs []templateReference        // Templates used by projects
+	skipDirs     bool                       // Whether directories can't be checked, like in templates
+	overrides    bool                       // Whether the files being checked override existing settings
+	visitedFiles map[string]bool
+}
+
+// validateConfiguration checks the configuration file, its overlay files and their included files
+func validateConfiguration(path string, overlays []string) []configDiagnostic {
+	validator := &configValidator{
+		definitions:  make(map[string]string),
+		globalCmds:   make(map[string]bool),
+		projectCmds:  make(map[string]map[string]bool),
+		templateCmds: make(map[string]map[string]bool),
+		visitedFiles: make(map[string]bool),
+	}
+
+	validator.validateFile(path)
+
+	// Overlay files may set only some keys of the settings they override
+	validator.overrides = true
+	for _, overlayPath := range overlays {
+		if !validator.visitedFiles[overlayPath] {
+			validator.validateFile(overlayPath)
+		}
+	}
+
+	validator.validateTemplateReferences()
+	validator.validateRunnerReferences()
+	validator.validateCommandReferences()
+
+	// Sort by file visiting order, then by position
+	fileOrder := make(map[string]int)
+	for i, file := range validator.files {
+		fileOrder[file] = i
+	}
+
+	sort.SliceStable(validator.diagnostics, func(i, j int) bool {
+		a, b := validator.diagnostics[i], validator.diagnostics[j]
+		if fileOrder[a.file] != fileOrder[b.file] {
+			return fileOrder[a.file] < fileOrder[b.file]
+		}
+		if a.line != b.line {
+			return a.line < b.line
+		}
+		return a.column < b.column
+	})
+
+	return validator.diagnostics
+}
+
+// runValidateCommand validates the configuration and exits with a non-zero code on problems
+func runValidateCommand() {
+	diagnostics := validateConfiguration(configurationPath, overlayPaths)
+
+	errorCount := 0
+	for _, diag := range diagnostics {
+		fmt.Println(diag.String())
+		if !diag.warning {
+			errorCount++
+		}
+	}
+
+	if errorCount == 0 {
+		displayPaths := []string{getDisplayPath(configurationPath)}
+		for _, overlayPath := range overlayPaths {
+			displayPaths = append(displayPaths, getDisplayPath(overlayPath))
+		}
+
+		logger.Info("Configuration is valid: %s", strings.Join(displayPaths, ", "))
+		os.Exit(0)
+	}
+
+	logger.Error("Found %d problem(s) in configuration", errorCount)
+	os.Exit(1)
+}
+
+// report adds a diagnostic at the position of a token
+func (v *configValidator) report(tk *token.Token, format string, args ...any) {
+	diag := configDiagnostic{file: v.file, message: fmt.Sprintf(format, args...)}
+	if tk != nil {
+		diag.line = tk.Position.Line
+		diag.column = tk.Position.Column
+	}
+	v.diagnostics = append(v.diagnostics, diag)
+}
+
+// warn adds a diagnostic that doesn't make the configuration invalid
+func (v *configValidator) warn(tk *token.Token, format string, args ...any) {
+	v.report(tk, format, args...)
+	v.diagnostics[len(v.diagnostics)-1].warning = true
+}
+
+// validateFile checks a single configuration file and the files it includes
+func (v *configValidator) validateFile(path string) {
+	v.visitedFiles[path] = true
+	v.files = append(v.files, path)
+	v.file = path
+
+	content, err := readYamlFile(path)
+	if err != nil {
+		v.report(nil, "Failed to read configuration file: %v", err)
+		return
+	}
+
+	content = replaceEnvironmentVariables(replaceRootPathPlaceholders(content), true)
+
+	file, err := parser.ParseBytes([]byte(content), 0)
+	if err != nil {
+		var yamlErr yaml.Error
+		if errors.As(err, &yamlErr) {
+			v.report(yamlErr.GetToken(), "%s", yamlErr.GetMessage())
+		} else {
+			v.report(nil, "%v", err)
+		}
+		return
+	}
+
+	if len(file.Docs) == 0 || file.Docs[0].Body == nil {
+		return // Empty file
+	}
+
+	body := unwrapYamlNode(file.Docs[0].Body)
+	if _, isNull := body.(*ast.NullNode); isNull {
+		return
+	}
+
+	if getMappingValues(body) == nil {
+		v.report(body.GetToken(), "Configuration must be a map of settings")
+		return
+	}
+
+	keys := topLevelKeys
+	if v.overrides {
+		keys = getOverrideConfigKeys(topLevelKeys)
+	}
+
+	baseDir := filepath.Dir(path)
+	entries := v.validateMap(body, nil, "", keys, baseDir)
+
+	// Check included files after the current one
+	if entry, ok := entries["include"]; ok {
+		for _, node := range getSequenceOrScalar(entry.Value) {
+			v.validateInclude(node, baseDir)
+		}
+	}
+}
+
+// validateInclude checks an `include` entry and validates the files it points to
+func (v *configValidator) validateInclude(node ast.Node, baseDir string) {
+	pattern, ok := getStringValue(node)
+	if !ok {
+		return // Already reported as an invalid value
+	}
+
+	includedFiles, err := resolveIncludedFiles(pattern, baseDir)
+	if err != nil {
+		v.report(node.GetToken(), "%v", err)
+		return
+	}
+
+	currentFile := v.file
+	for _, includedFile := range includedFiles {
+		if v.visitedFiles[includedFile] {
+			continue
+		}
+
+		if info, err := os.Stat(includedFile); err != nil || info.IsDir() {
+			v.report(node.GetToken(), "Included configuration file not found. Path: %s", includedFile)
+			continue
+		}
+
+		v.validateFile(includedFile)
+		v.file = currentFile
+	}
+}
+
+// validateMap checks the keys of a mapping node and returns its entries by key
+func (v *configValidator) validateMap(
+	node ast.Node,
+	parentToken *token.Token,
+	path string,
+	keys []configKey,
+	dir string,
+) map[string]*ast.MappingValueNode {
+	entries := make(map[string]*ast.MappingValueNode)
+
+	for _, entry := range getMappingValues(node) {
+		if _, isMergeKey := entry.Key.(*ast.MergeKeyNode); isMergeKey {
+			continue
+		}
+
+		name := entry.Key.GetToken().Value
+		if _, duplicate := entries[name]; duplicate {
+			v.report(entry.Key.GetToken(), "Duplicate key `%s` in %s", name, describeConfigPath(path))
+			continue
+		}
+
+		entries[name] = entry
+
+		if _, ok := findConfigKey(keys, name); !ok {
+			v.report(entry.Key.GetToken(), "Unknown key `%s` in %s", name, describeConfigPath(path))
+		}
+	}
+
+	// Nested paths are resolved against the `dir` of this level
+	if entry, ok := entries["dir"]; ok {
+		if dirPath, ok := getStringValue(entry.Value); ok {
+			dir = v.validateDir(entry.Value, joinConfigPath(path, "dir"), dirPath, dir)
+		}
+	}
+
+	for _, key := range keys {
+		entry, ok := entries[key.name]
+		if !ok {
+			if key.required {
+				v.report(parentToken, "Missing required key `%s` in %s", key.name, describeConfigPath(path))
+			}
+			continue
+		}
+
+		v.validateValue(entry.Value, entry.Key.GetToken(), joinConfigPath(path, key.name), key, dir)
+	}
+
+	return entries
+}
+
+// validateDir checks that a directory exists and returns its resolved path
+func (v *configValidator) validateDir(node ast.Node, path, dirPath, baseDir string) string {
+	// Skip values that are only known at execution time
+	if v.skipDirs || strings.Contains(dirPath, "${") || strings.Contains(dirPath, "{{") {
+		return baseDir
+	}
+
+	resolvedDir := resolveFilePath(dirPath, baseDir)
+	if info, err := os.Stat(resolvedDir); err != nil || !info.IsDir() {
+		v.report(node.GetToken(), "Directory `%s` set in `%s` does not exist", dirPath, path)
+	}
+
+	return resolvedDir
+}
+
+// validateValue checks a value against the shape expected by its key
+func (v *configValidator) validateValue(node ast.Node, keyToken *token.Token, path string, key configKey, dir string) {
+	node = unwrapYamlNode(node)
+	if _, isAlias := node.(*ast.AliasNode); isAlias {
+		return // Aliased values are checked where they are defined
+	}
+
+	if _, isNull := node.(*ast.NullNode); isNull {
+		if key.required {
+			v.report(keyToken, "Invalid value for `%s`: must not be empty", path)
+		}
+		return
+	}
+
+	switch key.kind {
+	case stringValue:
+		value, ok := getStringValue(node)
+		if !ok {
+			v.reportType(node, path, "a string")
+		} else if len(key.allowed) > 0 && !utils.SliceContainsValue(key.allowed, value) {
+			v.reportType(node, path, "one of `"+strings.Join(key.allowed, "`, `")+"`")
+		}
+
+	case numberValue:
+		if !isNumberNode(node) {
+			v.reportType(node, path, "a number")
+		}
+
+	case durationValue:
+		if isNumberNode(node) {
+			break
+		}
+
+		if value, ok := getStringValue(node); !ok || !strings.Contains(value, "{{") {
+			if _, valid := parseTimeout(value); !valid {
+				v.reportType(node, path, "a positive duration like `30s`, `5m` or `1h30m`, or a number of seconds")
+			}
+		}
+
+	case integerValue:
+		if _, ok := node.(*ast.IntegerNode); !ok {
+			v.reportType(node, path, "an integer")
+		}
+
+	case boolValue:
+		if _, ok := node.(*ast.BoolNode); !ok {
+			v.reportType(node, path, "`true` or `false`")
+		}
+
+	case scalarValue:
+		switch node.(type) {
+		case *ast.StringNode, *ast.LiteralNode, *ast.IntegerNode, *ast.FloatNode, *ast.BoolNode:
+		default:
+			v.reportType(node, path, "a string, a number or a boolean")
+		}
+
+	case paramsValue:
+		v.validateParams(node, path)
+
+	case stringListValue:
+		if !isStringOrStringList(node) {
+			v.reportType(node, path, "a string or a list of strings")
+		}
+
+	case runValue:
+		v.validateRunSteps(node, keyToken, path, dir)
+
+	case exitCodesValue:
+		if !isIntegerOrIntegerList(node) {
+			v.reportType(node, path, "an exit code or a list of exit codes")
+		}
+
+	case dotenvValue:
+		v.validateDotenv(node, path, dir)
+
+	case envValue:
+		v.validateEnvMap(node, path, dir)
+
+	case inheritEnvValue:
+		if _, isBool := node.(*ast.BoolNode); !isBool && !isStringOrStringList(node) {
+			v.reportType(node, path, "a boolean or a list of variable names")
+		}
+
+	case varsValue:
+		v.validateScalarMap(node, path, "a map of variables")
+		if path == "vars" {
+			for _, entry := range getMappingValues(node) {
+				v.addDefinition(entry.Key.GetToken(), "Variable", path, entry.Key.GetToken().Value)
+			}
+		}
+
+	case watchValue:
+		if getMappingValues(node) != nil {
+			v.validateMap(node, keyToken, path, getNestedConfigKeys(key.kind), dir)
+		} else if !isStringOrStringList(node) {
+			v.reportType(node, path, "a file pattern, a list of file patterns or a map with `include` and `exclude`")
+		}
+
+	case commandValue:
+		v.validateCommand(node, keyToken, path, dir)
+
+	case afterCommandValue:
+		if entries := getMappingValues(node); entries != nil && hasAnyConfigKey(entries, afterKeys) {
+			v.validateMap(node, keyToken, path, getNestedConfigKeys(key.kind), dir)
+		} else {
+			v.validateCommand(node, keyToken, path, dir)
+		}
+
+	case commandMapValue, commandOverrideMapValue:
+		v.validateCommandMap(node, path, dir)
+
+	case projectMapValue:
+		v.validateProjects(node, path, dir, projectKeys)
+
+	case projectOverrideMapValue:
+		v.validateProjects(node, path, dir, getOverrideConfigKeys(projectKeys))
+
+	case profileMapValue:
+		v.validateProfiles(node, path, dir)
+
+	case templateMapValue:
+		v.validateTemplates(node, path)
+
+	case runnerMapValue:
+		v.validateRunners(node, path)
+
+	case restartValue:
+		if getMappingValues(node) != nil {
+			v.validateMap(node, keyToken, path, getNestedConfigKeys(key.kind), dir)
+		} else if _, ok := node.(*ast.BoolNode); !ok {
+			v.reportType(node, path, "`true`, `false` or a map of restart settings")
+		}
+
+	case awaitsValue:
+		if getMappingValues(node) != nil {
+			v.validateMap(node, keyToken, path, getNestedConfigKeys(key.kind), dir)
+		} else if !isIntegerOrIntegerList(node) {
+			v.reportType(node, path, "a port, a list of ports or a map of awaits settings")
+		}
+
+	case portsValue:
+		if !isIntegerOrIntegerList(node) {
+			v.reportType(node, path, "a port or a list of ports")
+		}
+
+	case conditionValue:
+		if getMappingValues(node) != nil {
+			v.validateMap(node, keyToken, path, getNestedConfigKeys(key.kind), dir)
+		} else if sequence, ok := node.(*ast.SequenceNode); ok {
+			v.validateConditionList(sequence, keyToken, path, dir)
+		} else {
+			v.reportType(node, path, "a map of conditions or a list of conditions")
+		}
+
+	case conditionListValue:
+		if sequence, ok := node.(*ast.SequenceNode); ok {
+			v.validateConditionList(sequence, keyToken, path, dir)
+		} else {
+			v.reportType(node, path, "a list of conditions")
+		}
+	}
+}
+
+// reportType adds a diagnostic for a value of the wrong type
+func (v *configValidator) reportType(node ast.Node, path, expected string) {
+	v.report(node.GetToken(), "Invalid value for `%s`: must be %s", path, expected)
+}
+
+// validateScalarMap checks a map of strings, numbers and booleans
+func (v *configValidator) validateScalarMap(node ast.Node, path, expected string) {
+	entries := getMappingValues(node)
+	if entries == nil {
+		v.reportType(node, path, expected)
+		return
+	}
+
+	for _, entry := range entries {
+		value := unwrapYamlNode(entry.Value)
+		switch value.(type) {
+		case *ast.StringNode, *ast.LiteralNode, *ast.IntegerNode, *ast.FloatNode, *ast.BoolNode, *ast.NullNode, *ast.AliasNode:
+			continue
+		}
+		v.reportType(value, joinConfigPath(path, entry.Key.GetToken().Value), "a string, a number or a boolean")
+	}
+}
+
+// validateDotenv checks environment files, written as paths or detailed maps
+func (v *configValidator) validateDotenv(node ast.Node, path, dir string) {
+	items := []ast.Node{node}
+	sequence, isList := node.(*ast.SequenceNode)
+	if isList {
+		items = sequence.Values
+	}
+
+	for i, item := range items {
+		item = unwrapYamlNode(item)
+		itemPath := path
+		if isList {
+			itemPath = fmt.Sprintf("%s[%d]", path, i)
+		}
+
+		if getMappingValues(item) != nil {
+			v.validateMap(item, item.GetToken(), itemPath, getNestedConfigKeys(dotenvValue), dir)
+		} else if _, ok := getStringValue(item); !ok {
+			v.reportType(item, itemPath, "a file path, a map with a `path` key or a list of them")
+		}
+	}
+}
+
+// validateEnvMap checks a map of environment variables, whose values can be detailed as maps
+func (v *configValidator) validateEnvMap(node ast.Node, path, dir string) {
+	entries := getMappingValues(node)
+	if entries == nil {
+		v.reportType(node, path, "a map of environment variables")
+		return
+	}
+
+	for _, entry := range entries {
+		value := unwrapYamlNode(entry.Value)
+		entryPath := joinConfigPath(path, entry.Key.GetToken().Value)
+
+		if settings := getMappingValues(value); settings != nil {
+			v.validateMap(value, entry.Key.GetToken(), entryPath, getNestedConfigKeys(envValue), dir)
+
+			// The value is either set or computed
+			hasValue := hasAnyConfigKey(settings, []configKey{{name: "value"}})
+			hasScript := hasAnyConfigKey(settings, []configKey{{name: "sh"}})
+			if hasValue == hasScript {
+				v.report(entry.Key.GetToken(), "Environment variable `%s` must set either `value` or `sh`", entryPath)
+			}
+			continue
+		}
+
+		switch value.(type) {
+		case *ast.StringNode, *ast.LiteralNode, *ast.IntegerNode, *ast.FloatNode, *ast.BoolNode, *ast.NullNode, *ast.AliasNode:
+			continue
+		}
+		v.reportType(value, entryPath, "a string, a number, a boolean or a map with `value` or `sh`")
+	}
+}
+
+// validateParams checks a list of command parameters
+func (v *configValidator) validateParams(node ast.Node, path string) {
+	sequence, ok := node.(*ast.SequenceNode)
+	if !ok {
+		v.reportType(node, path, "a list of parameters")
+		return
+	}
+
+	declaredNames := make(map[string]bool)
+	for i, item := range sequence.Values {
+		item = unwrapYamlNode(item)
+		itemPath := path + "[" + strconv.Itoa(i) + "]"
+
+		if getMappingValues(item) == nil {
+			v.reportType(item, itemPath, "a map with a `name` key")
+			continue
+		}
+
+		entries := v.validateMap(item, item.GetToken(), itemPath, paramKeys, "")
+		if nameEntry, ok := entries["name"]; ok {
+			if name, ok := getStringValue(nameEntry.Value); ok {
+				if declaredNames[name] {
+					v.report(nameEntry.Value.GetToken(), "Parameter `%s` is declared more than once in `%s`", name, path)
+				}
+				declaredNames[name] = true
+			}
+		}
+
+		// Defaults rendered from templates are checked when the command is run
+		defaultEntry, hasDefault := entries["default"]
+		allowedEntry, hasAllowed := entries["allowed"]
+		if !hasDefault || !hasAllowed {
+			continue
+		}
+
+		allowedSequence, ok := unwrapYamlNode(allowedEntry.Value).(*ast.SequenceNode)
+		defaultNode, isScalar := unwrapYamlNode(defaultEntry.Value).(ast.ScalarNode)
+		if !ok || !isScalar || strings.Contains(defaultNode.GetToken().Value, "{{") {
+			continue
+		}
+
+		defaultValue := defaultNode.GetToken().Value
+		allowedValues := []string{}
+		for _, allowedNode := range allowedSequence.Values {
+			if scalar, ok := unwrapYamlNode(allowedNode).(ast.ScalarNode); ok {
+				allowedValues = append(allowedValues, scalar.GetToken().Value)
+			}
+		}
+
+		if !utils.SliceContainsValue(allowedValues, defaultValue) {
+			v.reportType(defaultEntry.Value, itemPath+".default", "one of `"+strings.Join(allowedValues, "`, `")+"`")
+		}
+	}
+}
+
+// validateConditionList checks each condition of a list
+func (v *configValidator) validateConditionList(sequence *ast.SequenceNode, keyToken *token.Token, path, dir string) {
+	for i, item := range sequence.Values {
+		v.validateValue(item, keyToken, fmt.Sprintf("%s[%d]", path, i), configKey{kind: conditionValue}, dir)
+	}
+}
+
+// validateRunSteps checks a command or a list of commands and detailed steps
+func (v *configValidator) validateRunSteps(node ast.Node, keyToken *token.Token, path, dir string) {
+	if _, ok := getStringValue(node); ok {
+		return
+	}
+
+	sequence, ok := node.(*ast.SequenceNode)
+	if !ok {
+		v.reportType(node, path, "a command or a list of commands")
+		return
+	}
+
+	for i, item := range sequence.Values {
+		item = unwrapYamlNode(item)
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+
+		if getMappingValues(item) != nil {
+			v.validateMap(item, item.GetToken(), itemPath, getNestedConfigKeys(runValue), dir)
+		} else if _, ok := getStringValue(item); !ok {
+			v.reportType(item, itemPath, "a command or a map with a `run` key")
+		}
+	}
+}
+
+// validateCommand checks a command written as a string, a list or a detailed map
+func (v *configValidator) validateCommand(node ast.Node, keyToken *token.Token, path, dir string) {
+	node = unwrapYamlNode(node)
+
+	if getMappingValues(node) != nil {
+		keys := commandKeys
+		if v.overrides || strings.HasPrefix(path, "profiles.") {
+			keys = getOverrideConfigKeys(commandKeys) // Overrides are merged onto existing commands
+		} else if hasAnyConfigKey(getMappingValues(node), []configKey{{name: "extends"}}) {
+			keys = getOverrideConfigKeys(commandKeys) // Required keys may be inherited
+		}
+
+		entries := v.validateMap(node, keyToken, path, keys, dir)
+		if extendsEntry, ok := entries["extends"]; ok {
+			v.addCommandReference(extendsEntry.Value, path, "extended")
+		}
+
+		if depsEntry, ok := entries["deps"]; ok {
+			depsNode := unwrapYamlNode(depsEntry.Value)
+			if sequence, isSequence := depsNode.(*ast.SequenceNode); isSequence {
+				for _, depNode := range sequence.Values {
+					v.addCommandReference(depNode, path, "required")
+				}
+			} else {
+				v.addCommandReference(depsNode, path, "required")
+			}
+		}
+		return
+	}
+
+	if !isStringOrStringList(node) {
+		v.reportType(node, path, "a command, a list of commands or a detailed command")
+	}
+}
+
+// validateCommandMap checks a map of named commands
+func (v *configValidator) validateCommandMap(node ast.Node, path, dir string) {
+	entries := getMappingValues(node)
+	if entries == nil {
+		v.reportType(node, path, "a map of commands")
+		return
+	}
+
+	for _, entry := range entries {
+		name := entry.Key.GetToken().Value
+		commandPath := joinConfigPath(path, name)
+
+		if path == "commands" {
+			v.addDefinition(entry.Key.GetToken(), "Command", path, name)
+		}
+
+		if isSectionPath(path, "commands") {
+			v.globalCmds[name] = true
+		}
+
+		if _, isNull := unwrapYamlNode(entry.Value).(*ast.NullNode); isNull {
+			v.reportType(entry.Value, commandPath, "a command, a list of commands or a detailed command")
+			continue
+		}
+
+		v.validateCommand(entry.Value, entry.Key.GetToken(), commandPath, dir)
+	}
+}
+
+// validateProjects checks the `projects` map, or the project overrides of a profile
+func (v *configValidator) validateProjects(node ast.Node, path, dir string, keys []configKey) {
+	entries := getMappingValues(node)
+	if entries == nil {
+		v.reportType(node, path, "a map of projects")
+		return
+	}
+
+	for _, entry := range entries {
+		name := entry.Key.GetToken().Value
+		projectPath := joinConfigPath(path, name)
+		if path == "projects" {
+			v.addDefinition(entry.Key.GetToken(), "Project", path, name)
+		}
+
+		project := unwrapYamlNode(entry.Value)
+		if getMappingValues(project) == nil {
+			v.reportType(project, projectPath, "a map of project settings")
+			continue
+		}
+
+		projectEntries := v.validateMap(project, entry.Key.GetToken(), projectPath, keys, dir)
+
+		if v.projectCmds[name] == nil {
+			v.projectCmds[name] = make(map[string]bool)
+		}
+
+		if templateEntry, ok := projectEntries["template"]; ok {
+			if templateName, ok := getStringValue(templateEntry.Value); ok {
+				v.templateRefs = append(v.templateRefs, templateReference{
+					file:     v.file,
+					token:    templateEntry.Value.GetToken(),
+					project:  name,
+					template: templateName,
+				})
+			}
+		}
+		if cmds, ok := projectEntries["cmds"]; ok {
+			for _, cmd := range getMappingValues(unwrapYamlNode(cmds.Value)) {
+				v.projectCmds[name][cmd.Key.GetToken().Value] = true
+			}
+		}
+	}
+}
+
+// validateTemplates checks the `templates` map
+func (v *configValidator) validateTemplates(node ast.Node, path string) {
+	entries := getMappingValues(node)
+	if entries == nil {
+		v.reportType(node, path, "a map of templates")
+		return
+	}
+
+	// Directories of templates depend on the projects using them
+	v.skipDirs = true
+	defer func() { v.skipDirs = false }()
+
+	for _, entry := range entries {
+		name := entry.Key.GetToken().Value
+		templatePath := joinConfigPath(path, name)
+		v.addDefinition(entry.Key.GetToken(), "Template", path, name)
+
+		template := unwrapYamlNode(entry.Value)
+		if getMappingValues(template) == nil {
+			v.reportType(template, templatePath, "a map of project settings")
+			continue
+		}
+
+		templateEntries := v.validateMap(template, entry.Key.GetToken(), templatePath, templateKeys, "")
+
+		if v.templateCmds[name] == nil {
+			v.templateCmds[name] = make(map[string]bool)
+		}
+
+		if cmds, ok := templateEntries["cmds"]; ok {
+			for _, cmd := range getMappingValues(unwrapYamlNode(cmds.Value)) {
+				v.templateCmds[name][cmd.Key.GetToken().Value] = true
+			}
+		}
+	}
+}
+
+// validateProfiles checks the `profiles` map
+func (v *configValidator) validateProfiles(node ast.Node, path, dir string) {
+	entries := getMappingValues(node)
+	if entries == nil {
+		v.reportType(node, path, "a map of profiles")
+		return
+	}
+
+	for _, entry := range entries {
+		name := entry.Key.GetToken().Value
+		profilePath := joinConfigPath(path, name)
+		v.addDefinition(entry.Key.GetToken(), "Profile", path, name)
+
+		profile := unwrapYamlNode(entry.Value)
+		if _, isNull := profile.(*ast.NullNode); isNull {
+			continue
+		}
+
+		if getMappingValues(profile) == nil {
+			v.reportType(profile, profilePath, "a map of overrides")
+			continue
+		}
+
+		v.validateMap(profile, entry.Key.GetToken(), profilePath, profileKeys, dir)
+	}
+}
+
+// validateRunners checks the `runners` map and collects the commands they reference
+func (v *configValidator) validateRunners(node ast.Node, path string) {
+	entries := getMappingValues(node)
+	if entries == nil {
+		v.reportType(node, path, "a map of runners")
+		return
+	}
+
+	for _, entry := range entries {
+		keyToken := entry.Key.GetToken()
+		runnerName, flags := extractRunnerNameAndFlags(keyToken.Value, []string{})
+		runnerPath := joinConfigPath(path, runnerName)
+		if path == "runners" {
+			v.addDefinition(keyToken, "Runner", path, runnerName)
+		}
+
+		for _, flagName := range flags {
+			if !utils.SliceContainsValue(runnerFlagNames, flagName) {
+				v.report(keyToken, "Unknown flag `%s` in runner `%s`. Must be `%s`", flagName, runnerName, strings.Join(runnerFlagNames, "` or `"))
+			}
+		}
+
+		runner := unwrapYamlNode(entry.Value)
+		if cmd, ok := getStringValue(runner); ok {
+			v.addRunnerReference(runner.GetToken(), runnerName, cmd)
+			continue
+		}
+
+		sequence, ok := runner.(*ast.SequenceNode)
+		if !ok || len(sequence.Values) == 0 {
+			v.reportType(runner, runnerPath, "a command or a list of commands")
+			continue
+		}
+
+		for i, item := range sequence.Values {
+			item = unwrapYamlNode(item)
+			itemPath := runnerPath + "[" + strconv.Itoa(i) + "]"
+
+			if cmd, ok := getStringValue(item); ok {
+				v.addRunnerReference(item.GetToken(), runnerName, cmd)
+				continue
+			}
+
+			if getMappingValues(item) == nil {
+				v.reportType(item, itemPath, "a command or a detailed runner command")
+				continue
+			}
+
+			itemEntries := v.validateMap(item, item.GetToken(), itemPath, runnerCommandKeys, "")
+			if cmdEntry, ok := itemEntries["cmd"]; ok {
+				if cmd, ok := getStringValue(cmdEntry.Value); ok {
+					v.addRunnerReference(cmdEntry.Value.GetToken(), runnerName, cmd)
+				}
+			}
+		}
+	}
+}
+
+// addRunnerReference records a command referenced by a runner
+func (v *configValidator) addRunnerReference(tk *token.Token, runnerName, cmd string) {
+	v.runnerRefs = append(v.runnerRefs, runnerReference{file: v.file, token: tk, runner: runnerName, cmd: cmd})
+}
+
+// addCommandReference records a command referenced by a command of the `commands` or `projects` sections
+func (v *configValidator) addCommandReference(node ast.Node, path, usage string) {
+	target, ok := getStringValue(node)
+	if !ok || strings.Contains(target, "{{") {
+		return // Already reported as an invalid value, or only known at execution time
+	}
+
+	pathParts := strings.Split(path, ".")
+	switch {
+	case pathParts[0] == "commands":
+		v.commandRefs = append(v.commandRefs, commandReference{file: v.file, token: node.GetToken(), path: path, target: target, usage: usage})
+	case pathParts[0] == "projects" && len(pathParts) > 1:
+		v.commandRefs = append(v.commandRefs, commandReference{file: v.file, token: node.GetToken(), path: path, project: pathParts[1], target: target, usage: usage})
+	}
+}
+
+// addDefinition records a top-level definition, reporting names defined more than once
+// and warning about commands, projects and runners shadowed by a built-in command
+func (v *configValidator) addDefinition(tk *token.Token, kind, section, name string) {
+	if usage, exists := builtinCommandUsages[name]; exists && utils.SliceContainsValue([]string{"commands", "projects", "runners"}, section) {
+		v.warn(tk, "%s `%s` can't be run as `%s`, which runs the built-in command instead", kind, name, usage)
+	}
+
+	if v.overrides {
+		return // Overlay files redefine existing names on purpose
+	}
+
+	key := section + "." + name
+	location := fmt.Sprintf("%s:%d", getDisplayPath(v.file), tk.Position.Line)
+
+	if existing, exists := v.definitions[key]; exists {
+		v.report(tk, "%s `%s` is already defined in `%s`", kind, name, existing)
+		return
+	}
+
+	v.definitions[key] = location
+}
+
+// validateRunnerReferences checks that runner commands point to existing definitions
+func (v *configValidator) validateRunnerReferences() {
+	for _, ref := range v.runnerRefs {
+		v.file = ref.file
+
+		tokens, err := shellquote.Split(ref.cmd)
+		if err != nil || len(tokens) == 0 {
+			v.report(ref.token, "Invalid format for command `%s` in runner `%s`", ref.cmd, ref.runner)
+			continue
+		}
+
+		target := tokens[0]
+		if v.globalCmds[target] {
+			continue
+		}
+
+		if projectName, found := strings.CutSuffix(target, ":*"); found {
+			if _, exists := v.projectCmds[projectName]; !exists {
+				v.report(ref.token, "Runner `%s` references unknown project `%s`", ref.runner, projectName)
+			}
+			continue
+		}
+
+		// Commands that don't reference a project are run as shell commands
+		projectName, commandName, found := strings.Cut(target, ":")
+		if commands, exists := v.projectCmds[projectName]; found && exists && !commands[commandName] {
+			v.report(ref.token, "Runner `%s` references unknown command `%s` of project `%s`", ref.runner, commandName, projectName)
+		}
+	}
+}
+
+// validateTemplateReferences checks that projects use existing templates and adds the template commands to them
+func (v *configValidator) validateTemplateReferences() {
+	for _, ref := range v.templateRefs {
+		v.file = ref.file
+
+		templateCmds, exists := v.templateCmds[ref.template]
+		if !exists {
+			v.report(ref.token, "Template `%s` used by project `%s` is not defined", ref.template, ref.project)
+			continue
+		}
+
+		for cmdName := range templateCmds {
+			v.projectCmds[ref.project][cmdName] = true
+		}
+	}
+}
+
+// validateCommandReferences checks that `extends` and `deps` values point to existing commands
+func (v *configValidator) validateCommandReferences() {
+	for _, ref := range v.commandRefs {
+		v.file = ref.file
+
+		if ref.project != "" && v.projectCmds[ref.project][ref.target] && ref.path != "projects."+ref.project+".cmds."+ref.target {
+			continue // Sibling command
+		}
+
+		if v.globalCmds[ref.target] {
+			continue
+		}
+
+		if projectName, commandName, found := strings.Cut(ref.target, ":"); found && v.projectCmds[projectName][commandName] {
+			continue
+		}
+
+		v.report(ref.token, "Command `%s` %s in `%s` is not defined", ref.target, ref.usage, ref.path)
+	}
+}
+
+// unwrapYamlNode returns the value of anchor and tag nodes
+func unwrapYamlNode(node ast.Node) ast.Node {
+	for {
+		switch value := node.(type) {
+		case *ast.AnchorNode:
+			node = value.Value
+		case *ast.TagNode:
+			node = value.Value
+		default:
+			return node
+		}
+	}
+}
+
+// getStringValue returns the value of a string node
+func getStringValue(node ast.Node) (string, bool) {
+	switch value := unwrapYamlNode(node).(type) {
+	case *ast.StringNode:
+		return value.Value, true
+	case *ast.LiteralNode:
+		return value.Value.Value, true
+	}
+	return "", false
+}
+
+// getSequenceOrScalar returns the items of a sequence node, or the node itself
+func getSequenceOrScalar(node ast.Node) []ast.Node {
+	node = unwrapYamlNode(node)
+	if sequence, ok := node.(*ast.SequenceNode); ok {
+		return sequence.Values
+	}
+	return []ast.Node{node}
+}
+
+// isNumberNode checks if a node is an integer or a decimal number
+func isNumberNode(node ast.Node) bool {
+	switch unwrapYamlNode(node).(type) {
+	case *ast.IntegerNode, *ast.FloatNode:
+		return true
+	}
+	return false
+}
+
+// isStringOrStringList checks if a node is a string or a list of strings
+func isStringOrStringList(node ast.Node) bool {
+	for _, item := range getSequenceOrScalar(node) {
+		if _, isAlias := unwrapYamlNode(item).(*ast.AliasNode); isAlias {
+			continue
+		}
+		if _, ok := getStringValue(item); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// isIntegerOrIntegerList checks if a node is an integer or a list of integers
+func isIntegerOrIntegerList(node ast.Node) bool {
+	for _, item := range getSequenceOrScalar(node) {
+		switch unwrapYamlNode(item).(type) {
+		case *ast.IntegerNode, *ast.AliasNode:
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// hasAnyConfigKey checks if mapping entries use any of the given keys
+func hasAnyConfigKey(entries []*ast.MappingValueNode, keys []configKey) bool {
+	for _, entry := range entries {
+		if _, ok := findConfigKey(keys, entry.Key.GetToken().Value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isSectionPath checks if a path is a top-level section, or the same section in a profile
+func isSectionPath(path, section string) bool {
+	if path == section {
+		return true
+	}
+
+	profilePath, found := strings.CutSuffix(path, "."+section)
+	return found && strings.Count(profilePath, ".") == 1 && strings.HasPrefix(profilePath, "profiles.")
+}
+
+// joinConfigPath appends a key to a dotted configuration path
+func joinConfigPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// describeConfigPath returns a readable location for a configuration path
+func describeConfigPath(path string) string {
+	if path == "" {
+		return "configuration root"
+	}
+	return "`" + path + "`"
+}
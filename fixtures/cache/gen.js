@@ -0,0 +1,8 @@
+const fs = require("fs");
+
+const sources = fs.readdirSync("src").filter((file) => file.endsWith(".txt")).sort();
+const content = sources.map((file) => fs.readFileSync(`src/${file}`, "utf8")).join("");
+
+fs.mkdirSync("out", { recursive: true });
+fs.writeFileSync("out/generated.txt", content);
+console.log(`generated => ${sources.join(", ")}`);
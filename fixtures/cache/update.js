@@ -0,0 +1,5 @@
+const fs = require("fs");
+
+fs.mkdirSync("out", { recursive: true });
+fs.writeFileSync("out/state.txt", "updated");
+console.log("state updated");
@@ -0,0 +1,7 @@
+const [name, delay = "0", exitCode = "0"] = process.argv.slice(2);
+
+console.log(`${name} => started`);
+setTimeout(() => {
+  console.log(`${name} => finished`);
+  process.exit(Number(exitCode));
+}, Number(delay));
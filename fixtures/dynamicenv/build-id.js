@@ -0,0 +1,5 @@
+const fs = require('fs');
+
+// Each evaluation is recorded, to check the value is computed once
+fs.appendFileSync('evaluations.log', 'evaluated\n');
+console.log('build-42');
@@ -0,0 +1,14 @@
+const fs = require('fs');
+
+// Waits for the evaluation of another variable, which only finishes when both run at the same time
+const [name, peer] = process.argv.slice(2);
+fs.writeFileSync(`${name}.flag`, '');
+
+const waitForPeer = () => {
+  if (fs.existsSync(`${peer}.flag`)) {
+    console.log(`${name} met ${peer}`);
+  } else {
+    setTimeout(waitForPeer, 50);
+  }
+};
+waitForPeer();
@@ -0,0 +1,6 @@
+const [name, exitCode = '0', delay = '0'] = process.argv.slice(2);
+
+setTimeout(() => {
+  console.log(`${name} => exit ${exitCode}`);
+  process.exit(Number(exitCode));
+}, Number(delay));
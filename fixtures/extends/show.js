@@ -0,0 +1,4 @@
+const path = require("path");
+const { NODE_ENV, REPORTER, SERVICE, LINT_MODE } = process.env;
+
+console.log(`${process.argv.slice(2).join(" ")} => cwd: ${path.basename(process.cwd())} env: ${NODE_ENV} reporter: ${REPORTER} service: ${SERVICE} lint: ${LINT_MODE}`);
@@ -0,0 +1,3 @@
+const path = require("path");
+console.log("cwd => " + path.basename(process.cwd()));
+console.log("service => " + process.env.SERVICE_NAME);
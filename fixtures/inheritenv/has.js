@@ -0,0 +1,3 @@
+for (const arg of process.argv.slice(2)) {
+  console.log(process.env[arg] === undefined ? `${arg} is not set` : `${arg} is set`);
+}
@@ -0,0 +1,16 @@
+const readline = require("readline");
+
+const prompt = readline.createInterface({ input: process.stdin, output: process.stdout });
+let answered = false;
+
+prompt.question("name? ", (name) => {
+  answered = true;
+  console.log(`answer => ${name} tty => ${Boolean(process.stdin.isTTY)}`);
+  prompt.close();
+});
+
+prompt.on("close", () => {
+  if (!answered) {
+    console.log("\nanswer => none");
+  }
+});
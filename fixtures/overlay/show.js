@@ -0,0 +1 @@
+console.log(`name => ${process.argv[2]} mode => ${process.env.MODE} level => ${process.env.LEVEL}`);
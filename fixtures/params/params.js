@@ -0,0 +1,2 @@
+console.log(`env => ${process.argv[2]} region => ${process.env.REGION} dry-run => ${process.env.DRY_RUN}`);
+console.log(`extra => ${process.argv.slice(3).join(" ")}`);
@@ -0,0 +1,4 @@
+const path = require("path");
+
+console.log(`cwd => ${path.basename(process.cwd())} backend => ${process.argv[2]}`);
+console.log(`db => ${process.env.DB_HOST}:${process.env.DB_PORT} extra => ${process.env.EXTRA}`);
@@ -0,0 +1,6 @@
+const { spawn } = require('child_process');
+
+// Leaves a process holding the output long after this one has exited
+spawn(process.execPath, ['-e', 'setTimeout(() => console.log("late output"), 3000)'], { stdio: 'inherit' }).unref();
+console.log('parent done');
+process.exit(Number(process.argv[2] || 0));
@@ -0,0 +1,8 @@
+const path = require('path');
+
+const [name, exitCode = '0', delay = '0'] = process.argv.slice(2);
+
+setTimeout(() => {
+  console.log(`${name} => ${path.basename(process.cwd())}`);
+  process.exit(Number(exitCode));
+}, Number(delay));
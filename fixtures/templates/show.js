@@ -0,0 +1,4 @@
+const path = require("path");
+const { NODE_ENV, LOG_LEVEL } = process.env;
+
+console.log(`${process.argv.slice(2).join(" ")} => cwd: ${path.basename(process.cwd())} env: ${NODE_ENV} log: ${LOG_LEVEL}`);
@@ -0,0 +1,13 @@
+const [mode] = process.argv.slice(2);
+
+if (mode === "stubborn") {
+  process.on("SIGTERM", () => console.log("hang => ignoring SIGTERM"));
+}
+
+if (mode === "tree") {
+  // The child must be stopped along with its parent
+  require("child_process").spawn(process.execPath, ["-e", "setTimeout(() => console.log('hang => child survived'), 2000)"], { stdio: "inherit" });
+}
+
+console.log("hang => started");
+setInterval(() => {}, 1000);
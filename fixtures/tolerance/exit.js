@@ -0,0 +1,4 @@
+const [name, exitCode = '0'] = process.argv.slice(2);
+
+console.log(`${name} => exit ${exitCode}`);
+process.exit(Number(exitCode));
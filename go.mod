@@ -5,6 +5,7 @@ go 1.23.0
 toolchain go1.23.3
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/bmatcuk/doublestar/v4 v4.8.1
 	github.com/fsnotify/fsnotify v1.8.0
 	github.com/goccy/go-yaml v1.17.1
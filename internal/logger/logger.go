@@ -2,6 +2,8 @@ package logger
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/go-navi/navi/internal/utils"
@@ -18,6 +20,9 @@ var AnsiColors = []string{
 	"\033[0;95m", // Bright Magenta
 }
 
+// Destination of the messages, switched to stderr when stdout is reserved for data
+var Output io.Writer = os.Stdout
+
 // Tracks current color in the rotation
 var currentColorIndex = 0
 
@@ -57,7 +62,7 @@ func GetLogPrefixColor() string {
 
 // formatMessage formats log messages with appropriate color and prefix
 func formatMessage(prefix, msgType, color, format string, args ...any) string {
-	msg := fmt.Sprintf(format, args...)
+	msg := MaskSecrets(fmt.Sprintf(format, args...))
 
 	if utils.IsRunningInTestMode() {
 		if prefix == "" {
@@ -78,7 +83,7 @@ func print(prefix, msgType, color, format string, args ...any) {
 		prefix = ""
 	}
 
-	fmt.Println(formatMessage(prefix, msgType, color, format, args...))
+	fmt.Fprintln(Output, formatMessage(prefix, msgType, color, format, args...))
 }
 
 // Error prints formatted error message in red
@@ -103,12 +108,14 @@ func WarnWithPrefix(prefix string, format string, args ...any) {
 
 // Info prints information message in green
 func Info(format string, args ...any) {
+	msg := MaskSecrets(fmt.Sprintf(format, args...))
+
 	if utils.IsRunningInTestMode() {
-		fmt.Printf(format+"\n", args...)
+		fmt.Fprintln(Output, msg)
 		return
 	}
 
-	fmt.Printf("%s%s%s\n", colorGreen, fmt.Sprintf(format, args...), colorReset)
+	fmt.Fprintf(Output, "%s%s%s\n", colorGreen, msg, colorReset)
 }
 
 // InfoWithPrefix prints info with a custom prefix
@@ -118,10 +125,12 @@ func InfoWithPrefix(prefix string, format string, args ...any) {
 		return
 	}
 
+	msg := MaskSecrets(fmt.Sprintf(format, args...))
+
 	if utils.IsRunningInTestMode() {
-		fmt.Printf("%s "+format+"\n", append([]any{prefix}, args...)...)
+		fmt.Fprintf(Output, "%s %s\n", prefix, msg)
 		return
 	}
 
-	fmt.Printf("%s %s%s%s\n", prefix, colorGreen, fmt.Sprintf(format, args...), colorReset)
+	fmt.Fprintf(Output, "%s %s%s%s\n", prefix, colorGreen, msg, colorReset)
 }
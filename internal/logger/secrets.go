@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Text printed instead of secret values
+const SecretMask = "***"
+
+// Values of variables detected as secret by their name that are shorter than this are not masked,
+// since they would hide unrelated text
+const MinSecretLength = 4
+
+// Values too common in the output to be masked when detected by name, compared case-insensitively
+var genericSecretValues = []string{
+	"true", "false", "null", "none", "test", "local", "debug", "admin",
+	"localhost", "development", "production", "staging",
+}
+
+// Secret values hidden from the output, and the replacer masking them
+var secretValues []string
+var secretReplacer *strings.Replacer
+var secretMutex sync.RWMutex
+
+// Names of the variables whose secret value can't be masked, already reported
+var unmaskedSecretNames = make(map[string]bool)
+
+// RegisterSecret adds the value of a variable to hide from every line printed by navi.
+// Values of variables detected by their name that are too short or too generic are left
+// visible, with a warning. Variables explicitly marked as secret are always masked
+func RegisterSecret(name, value string, explicit bool) {
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+
+	if !explicit && len(strings.TrimSpace(value)) < MinSecretLength || slices.Contains(genericSecretValues, strings.ToLower(strings.TrimSpace(value))) {
+		secretMutex.Lock()
+		reported := unmaskedSecretNames[name]
+		unmaskedSecretNames[name] = true
+		secretMutex.Unlock()
+
+		if !reported {
+			Warn("Secret `%s` is not masked in the output, since its value is shorter than %d characters or too generic", name, MinSecretLength)
+		}
+		return
+	}
+
+	secretMutex.Lock()
+	defer secretMutex.Unlock()
+
+	if slices.Contains(secretValues, value) {
+		return
+	}
+
+	// Longer values are masked first, so a secret containing another one is fully hidden
+	secretValues = append(secretValues, value)
+	slices.SortFunc(secretValues, func(a, b string) int {
+		return len(b) - len(a)
+	})
+
+	replacements := make([]string, 0, len(secretValues)*2)
+	for _, secret := range secretValues {
+		replacements = append(replacements, secret, SecretMask)
+	}
+	secretReplacer = strings.NewReplacer(replacements...)
+}
+
+// MaskSecrets replaces the registered secret values of a text with `***`
+func MaskSecrets(text string) string {
+	secretMutex.RLock()
+	defer secretMutex.RUnlock()
+
+	if secretReplacer == nil {
+		return text
+	}
+	return secretReplacer.Replace(text)
+}
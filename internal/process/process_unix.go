@@ -31,6 +31,34 @@ func SetupNewProcessGroup(cmd *exec.Cmd) {
 	}
 }
 
+// Prepares an interactive command to join a new process group, placed in the foreground of the terminal
+// to read from it and receive its signals. Returns whether the foreground was given to the command
+func SetupForegroundProcessGroup(cmd *exec.Cmd) bool {
+	ttyFd := int(os.Stdin.Fd())
+
+	// Without a terminal whose foreground is navi, there is no foreground to give
+	foregroundPgid, err := unix.IoctlGetInt(ttyFd, unix.TIOCGPGRP)
+	if err != nil || foregroundPgid != unix.Getpgrp() {
+		SetupNewProcessGroup(cmd)
+		return false
+	}
+
+	cmd.SysProcAttr = &unix.SysProcAttr{
+		Foreground: true,
+		Ctty:       ttyFd,
+	}
+	return true
+}
+
+// Gives the foreground of the terminal back to navi once an interactive command has finished
+func RestoreForeground() {
+	// navi is a background process at this point, which would be stopped by SIGTTOU
+	signal.Ignore(unix.SIGTTOU)
+	defer signal.Reset(unix.SIGTTOU)
+
+	unix.IoctlSetPointerInt(int(os.Stdin.Fd()), unix.TIOCSPGRP, unix.Getpgrp())
+}
+
 // SIGTERM to all processes
 func TerminateProcessGroup() {
 	for _, cmd := range ProcessRegistry {
@@ -63,6 +91,16 @@ func InterruptProcess(cmd *exec.Cmd) {
 	unix.Kill(-cmd.Process.Pid, unix.SIGINT)
 }
 
+// SIGKILL a process group
+func KillProcessGroup(cmd *exec.Cmd) {
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+
+	unix.Kill(-cmd.Process.Pid, unix.SIGKILL)
+	cmd.Process.Kill()
+}
+
 // SIGKILL a process
 func KillProcess(cmd *exec.Cmd) {
 	if cmd == nil || cmd.Process == nil {
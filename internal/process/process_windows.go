@@ -44,6 +44,16 @@ func SetupNewProcessGroup(cmd *exec.Cmd) {
 	}
 }
 
+// Prepares an interactive command to join the main process group, which receives the console signals
+func SetupForegroundProcessGroup(cmd *exec.Cmd) bool {
+	SetupProcessGroup(cmd)
+	return false
+}
+
+func RestoreForeground() {
+	// For Unix
+}
+
 // SIGINT to the whole process group
 func TerminateProcessGroup() {
 	windows.GenerateConsoleCtrlEvent(windows.CTRL_C_EVENT, uint32(os.Getpid()))
@@ -69,6 +79,11 @@ func InterruptProcess(cmd *exec.Cmd) { // in Windows, SIGTERM will have the same
 	TerminateProcess(cmd)
 }
 
+// SIGKILL a process tree
+func KillProcessGroup(cmd *exec.Cmd) {
+	KillProcess(cmd) // taskkill already stops the whole process tree
+}
+
 // SIGKILL a process
 func KillProcess(cmd *exec.Cmd) {
 	if cmd == nil || cmd.Process == nil {
@@ -37,6 +37,7 @@ type TestResult struct {
 	WorkingDir    string
 	ExecutionTime time.Duration
 	CommandOutput string
+	ExitCode      int // Exit code of navi (-1 = forced to finish)
 }
 
 // CleanupFunctions stores and manages functions that revert test changes
@@ -60,6 +61,7 @@ func (cf *CleanupFunctions) ExecuteAll() {
 // captureCommandOutput sets up pipes to collect stdout and stderr from a command
 func captureCommandOutput(cmd *exec.Cmd) (func() string, error) {
 	capturedText := ""
+	var captureLock sync.Mutex
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to retrieve stdout logs: %v", err)
@@ -79,7 +81,9 @@ func captureCommandOutput(cmd *exec.Cmd) (func() string, error) {
 		scanner := bufio.NewScanner(stream)
 		for scanner.Scan() {
 			line := scanner.Text()
+			captureLock.Lock()
 			capturedText += line + "\n"
+			captureLock.Unlock()
 			if displayOutputInRealtime {
 				fmt.Printf("\033[0;32moutput ⟫\033[0m %s\n", line)
 			}
@@ -213,6 +217,11 @@ func (result *TestResult) AssertMinDuration(min time.Duration) {
 	result.assertCondition(result.ExecutionTime >= min, "Expected execution duration to be at least %v. Finished in %v", min, result.ExecutionTime)
 }
 
+// AssertExitCode checks the exit code of navi
+func (result *TestResult) AssertExitCode(expected int) {
+	result.assertCondition(result.ExitCode == expected, "Expected exit code to be %d. Exited with %d", expected, result.ExitCode)
+}
+
 // AssertPortTimeoutError verifies port timeout error message is as expected
 func (result *TestResult) AssertPortTimeoutError(portNumber int, timeout float64) {
 	portErr := port.VerifyPortAvailability(portNumber, timeout, func() string { return "" })
@@ -517,6 +526,7 @@ func ExecuteTestCommand(
 	// Process command completion in background
 	commandCompletionChannel := make(chan error)
 	go func() {
+		getOutputFunc()
 		err := cmd.Wait()
 		testWaitGroup.Done()
 
@@ -544,6 +554,7 @@ func ExecuteTestCommand(
 	}
 
 	// Wait for command completion or timeout
+	exitCode := -1
 	select {
 	case <-time.After(timeout):
 		// Command timed out
@@ -559,6 +570,13 @@ func ExecuteTestCommand(
 			t.FailNow()
 		}
 		executionTime = time.Since(startTime)
+
+		var exitErr *exec.ExitError
+		if cmdErr == nil {
+			exitCode = 0
+		} else if errors.As(cmdErr, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
 	}
 
 	// Wait for test process to fully complete
@@ -585,6 +603,7 @@ func ExecuteTestCommand(
 		WorkingDir:    workingDir,
 		ExecutionTime: executionTime,
 		CommandOutput: getOutputFunc(),
+		ExitCode:      exitCode,
 	}
 }
 
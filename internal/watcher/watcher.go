@@ -386,3 +386,58 @@ func findDirectories(
 
 	return matchedDirs, nil
 }
+
+// FindMatchingFiles returns the sorted files matching the include patterns and none of the exclude patterns.
+// Patterns ending with `/` match all files inside the directory
+func FindMatchingFiles(patterns FilePatterns) ([]string, error) {
+	matchedFiles := make(map[string]bool)
+
+	for _, pattern := range patterns.Include {
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+
+		basePath, matchPattern := doublestar.SplitPattern(filepath.ToSlash(pattern))
+		if _, err := os.Stat(basePath); os.IsNotExist(err) {
+			continue
+		}
+
+		err := doublestar.GlobWalk(os.DirFS(basePath), matchPattern, func(match string, dirEntry fs.DirEntry) error {
+			if dirEntry.IsDir() {
+				return nil
+			}
+
+			matchedFile := filepath.ToSlash(filepath.Join(basePath, match))
+			if !isExcludedPath(matchedFile, patterns.Exclude) {
+				matchedFiles[matchedFile] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	files := make([]string, 0, len(matchedFiles))
+	for file := range matchedFiles {
+		files = append(files, file)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// isExcludedPath checks if a path matches an exclusion pattern, as a file or as directory content
+func isExcludedPath(path string, exclusions []string) bool {
+	for _, exclusion := range exclusions {
+		if !strings.HasSuffix(exclusion, "/") && matchesGlobPattern(exclusion, path) {
+			return true
+		}
+
+		if matchesGlobPattern(strings.TrimSuffix(exclusion, "/")+"/**", path) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -23,6 +23,9 @@ func cleanUp() {
 	os.Unsetenv("TEST_ENV_VAR")
 	os.Unsetenv("TEST_NUM_ENV_VAR")
 	os.Unsetenv("SPECIAL_CHARS")
+	os.Unsetenv("NAVI_CONFIG")
+	os.Unsetenv("NAVI_PROFILE")
+	os.Unsetenv("RELEASE_TOKEN")
 	cleanUpFunctions.ExecuteAll()
 }
 
@@ -320,6 +323,304 @@ func TestErrors(t *testing.T) {
 	result.AssertOccurrences("error-proj-13:test ⟫ Running project-level `after` command...", 1)
 	result.AssertContains("error-proj-13:test ⟫ ERROR: Fail during execution of after command(s): The command has failed with exit code exit status 1")
 
+	result = tester("-f", "./include/collision.yml", "api:cwd")
+	result.AssertContains("ERROR: Failed to load configuration from YAML file: Project `api` is defined in both `collision.yml:4` and `services/api.yml:2`")
+
+	result = tester("-f", "./include/missing.yml", "test")
+	result.AssertContains("ERROR: Failed to load configuration from YAML file: Included configuration file not found. Path: " + filepath.Join(fixturesDir, "include", "not-found.yml"))
+
+	result = tester("-f", "./vars/navi.yml", "show-undefined")
+	result.AssertContains("ERROR: Variable `missing` used in `echo \"{{ .vars.missing }}\"` is not defined")
+
+	result = tester("-f", "./params/navi.yml", "deploy")
+	result.AssertContains("ERROR: Missing required parameter `env` for command `deploy`")
+
+	result = tester("-f", "./params/navi.yml", "deploy", "--env=dev")
+	result.AssertContains("ERROR: Invalid value `dev` for parameter `env` of command `deploy`. Must be one of `staging`, `production`")
+
+	result = tester("-f", "./params/navi.yml", "deploy", "--force")
+	result.AssertContains("ERROR: Unknown parameter `force` for command `deploy`. Declared parameters: `env`, `region`, `dry-run`")
+
+	result = tester("-f", "./params/navi.yml", "deploy", "staging", "us-east-1", "yes", "now")
+	result.AssertContains("ERROR: Too many arguments for command `deploy`: `now`. Use `--` to pass extra arguments to the command")
+
+	result = tester("-f", "./params/invalid.yml", "deploy")
+	result.AssertContains("ERROR: Invalid default value `prod` for parameter `env` of command `deploy`. Must be one of `dev`, `staging`")
+	result.AssertNotContains("Executing")
+
+	result = tester("-f", "./params/invalid.yml", "validate")
+	result.AssertContains("invalid.yml:5:18: Invalid value for `commands.deploy.params[0].default`: must be one of `dev`, `staging`")
+
+	result = tester("-f", "./profiles/navi.yml", "-p", "unknown", "where")
+	result.AssertContains("ERROR: Failed to load configuration from YAML file: Profile `unknown` not found. Available profiles: `docker`, `staging`")
+
+	result = tester("-f", "./extends/errors.yml", "web:loop-a")
+	result.AssertContains("ERROR: Commands `web:loop-a` -> `web:loop-b` -> `web:loop-c` -> `web:loop-a` have a circular `extends` chain")
+
+	result = tester("-f", "./extends/errors.yml", "web:hook")
+	result.AssertContains("ERROR: Commands `web:hook` -> `web:hook post` -> `web:hook` have a circular `extends` chain")
+
+	result = tester("-f", "./extends/errors.yml", "web:missing")
+	result.AssertContains("ERROR: Command `unknown` extended by `web:missing` was not found")
+
+	result = tester("-f", "./extends/errors.yml", "validate")
+	result.AssertContains("errors.yml:13:18: Command `unknown` extended in `projects.web.cmds.missing` is not defined")
+
+	result = tester("-f", "./deps/errors.yml", "loop-a")
+	result.AssertContains("ERROR: Commands `loop-a` -> `loop-b` -> `loop-c` -> `loop-a` have a circular `deps` chain")
+
+	result = tester("-f", "./deps/errors.yml", "missing")
+	result.AssertContains("ERROR: Command `unknown` required by `missing` was not found")
+
+	result = tester("-f", "./deps/errors.yml", "validate")
+	result.AssertContains("errors.yml:12:12: Command `unknown` required in `commands.missing` is not defined")
+
+	result = tester("-f", "./deps/navi.yml", "web:release")
+	result.AssertContains("ERROR: Dependency `web:broken` failed: The command has failed with exit code exit status 1")
+	result.AssertOccurrences("ERROR:", 1)
+	result.AssertNotContains("web:release => started")
+
+	result = tester("-f", "./timeout/navi.yml", "hang")
+	result.AssertSequentialOrder(
+		"\nhang => started",
+		"WARNING: Command timed out after 1s. Terminating...",
+		"ERROR: The command timed out after 1s",
+		"Running `after.failure` command...",
+		"\nafter failure => ran",
+	)
+
+	result = tester("-f", "./timeout/navi.yml", "stubborn")
+	result.AssertSequentialOrder(
+		"\nhang => ignoring SIGTERM",
+		"ERROR: The command timed out after 500ms",
+	)
+	result.AssertMinDuration(5 * time.Second)
+
+	result = tester("-f", "./timeout/navi.yml", "interactive")
+	result.AssertSequentialOrder(
+		"\nhang => started",
+		"ERROR: The command timed out after 1s",
+	)
+	result.AssertNotContains("hang => child survived")
+
+	result = tester("-f", "./timeout/navi.yml", "slow-pre")
+	result.AssertContains("ERROR: Command `pre` command failed: The command timed out after 500ms")
+	result.AssertNotContains("main => ran")
+
+	result = tester("-f", "./timeout/navi.yml", "suite")
+	result.AssertSequentialOrder(
+		"wait ⟫ ERROR: The command timed out after 1s",
+		"ERROR: A serial command in runner `suite` has failed",
+	)
+	result.AssertNotContains("hang ⟫")
+
+	result = tester("-f", "./timeout/errors.yml", "invalid")
+	result.AssertContains("ERROR: The `timeout` field for command `invalid` must be a duration like `30s` or `5m`, or a number of seconds")
+
+	result = tester("-f", "./timeout/errors.yml", "validate")
+	result.AssertContains("errors.yml:3:14: Invalid value for `commands.invalid.timeout`: must be a positive duration like `30s`, `5m` or `1h30m`, or a number of seconds")
+
+	result = tester("-f", "./conditions/errors.yml", "invalid")
+	result.AssertContains("ERROR: Invalid `if` condition: unknown condition `exist`. Must be one of `env`, `exists`, `os`, `cmd`, `all`, `any`, `not`")
+
+	result = tester("-f", "./conditions/errors.yml", "validate")
+	result.AssertContains("errors.yml:4:7: Unknown key `exist` in `commands.invalid.if`")
+
+	result = tester("-f", "./interactive/navi.yml", "prompts")
+	result.AssertContains("ERROR: Interactive commands `ask` and `ask` in runner `prompts` would run in parallel. Make them `serial` to share the terminal")
+
+	result = tester("-f", "./steps/navi.yml", "separate")
+	result.AssertSequentialOrder(
+		"Executing `cd sub`",
+		"Finished `cd sub` in ",
+		"\nafter-cd => steps",
+		"Finished `node where.js after-cd` in ",
+		"\nfailing => steps",
+		"Finished `node where.js failing 3` in ",
+		" with exit code 3",
+		"ERROR: The command has failed with exit code exit status 3",
+	)
+	result.AssertNotContains("never => steps")
+
+	result = tester("-f", "./steps/navi.yml", "slow")
+	result.AssertSequentialOrder(
+		"\nfirst => steps",
+		"Finished `node where.js first 0 600` in ",
+		"Executing `node where.js second 0 600`",
+		"ERROR: The command timed out after 1s",
+	)
+	result.AssertNotContains("second => steps")
+
+	result = tester("-f", "./steps/navi.yml", "background-fail")
+	result.AssertSequentialOrder(
+		"parent done",
+		"ERROR: The command has failed with exit code exit status 4",
+	)
+	result.AssertNotContains("late output")
+	result.AssertExitCode(4)
+
+	result = tester("-f", "./exitcodes/navi.yml", "fail")
+	result.AssertExitCode(3)
+
+	result = tester("-f", "./exitcodes/navi.yml", "slow")
+	result.AssertContains("ERROR: The command timed out after 500ms")
+	result.AssertExitCode(124)
+
+	result = tester("-f", "./exitcodes/navi.yml", "release")
+	result.AssertContains("ERROR: Dependency `fail` failed: The command has failed with exit code exit status 3")
+	result.AssertExitCode(3)
+
+	result = tester("-f", "./exitcodes/navi.yml", "failing-pre")
+	result.AssertContains("ERROR: Command `pre` command failed: The command has failed with exit code exit status 7")
+	result.AssertExitCode(7)
+
+	result = tester("-f", "./exitcodes/navi.yml", "checks")
+	result.AssertContains("third => exit 4")
+	result.AssertExitCode(2)
+
+	result = tester("-f", "./exitcodes/navi.yml", "suite")
+	result.AssertContains("ERROR: A dependent command in runner `suite` has failed or finished")
+	result.AssertNotContains("serve => exit 0")
+	result.AssertExitCode(3)
+
+	result = tester("-f", "./exitcodes/navi.yml", "--exit-code", "last", "checks")
+	result.AssertExitCode(4)
+
+	result = tester("-f", "./exitcodes/navi.yml", "--exit-code", "max", "checks")
+	result.AssertExitCode(5)
+
+	result = tester("-f", "./exitcodes/navi.yml", "--exit-code", "worst", "checks")
+	result.AssertContains("ERROR: Invalid value `worst` for `--exit-code`. Must be one of `first`, `last`, `max`")
+	result.AssertExitCode(1)
+
+	if runtime.GOOS != "windows" {
+		result = tester("-f", "./exitcodes/navi.yml", "killed")
+		result.AssertExitCode(137)
+
+		result = asyncTester("-f", "./exitcodes/navi.yml", "serve")
+		result.ExecuteAsyncExpectingError(8*time.Second, func(terminate func()) {
+			time.Sleep(1 * time.Second)
+			terminate()
+		})
+		result.AssertContains("WARNING: Received `terminated` signal")
+		result.AssertExitCode(143)
+	}
+
+	result = tester("-f", "./tolerance/navi.yml", "strict")
+	result.AssertContains("ERROR: The command has failed with exit code exit status 2")
+	result.AssertNotContains("never => exit 0", "which is tolerated")
+
+	result = tester("-f", "./tolerance/navi.yml", "checks")
+	result.AssertSequentialOrder(
+		"WARNING: `node exit.js first 3` failed with exit code 3, which is tolerated",
+		"\nsecond => exit 4",
+		"ERROR: The command has failed with exit code exit status 4",
+	)
+	result.AssertNotContains("third => exit 0")
+
+	result = tester("-f", "./tolerance/errors.yml", "invalid")
+	result.AssertContains("ERROR: The `ok_exit_codes` field for command `invalid` must be an exit code or a list of exit codes")
+
+	result = tester("-f", "./tolerance/errors.yml", "validate")
+	result.AssertContains(
+		"errors.yml:3:20: Invalid value for `commands.invalid.ok_exit_codes`: must be an exit code or a list of exit codes",
+		"errors.yml:6:26: Missing required key `run` in `commands.invalid.run[1]`",
+		"errors.yml:8:24: Invalid value for `commands.invalid.run[2].ok_exit_codes`: must be an exit code or a list of exit codes",
+		"errors.yml:9:9: Unknown key `retry` in `commands.invalid.run[2]`",
+		"errors.yml:14:9: Invalid value for `commands.numbered.run[1]`: must be a command or a map with a `run` key",
+	)
+
+	result = tester("-f", "./tolerance/errors.yml", "numbered")
+	result.AssertContains("ERROR: The `run` field for command `numbered` must be a command or a list of commands")
+	result.AssertNotContains("Executing")
+
+	result = tester("-f", "./templates/errors.yml", "api:start")
+	result.AssertContains("ERROR: Failed to load configuration from YAML file: Template `unknown` used by project `api` is not defined")
+
+	result = tester("-f", "./templates/errors.yml", "validate")
+	result.AssertContains("errors.yml:4:15: Template `unknown` used by project `api` is not defined")
+
+	result = tester("-f", "./validate/invalid.yml", "validate")
+	result.AssertSequentialOrder(
+		"invalid.yml:7:5: Unknown key `shel` in `commands.detailed`",
+		"invalid.yml:10:10: Invalid value for `commands.hooks.pre`: must be a command, a list of commands or a detailed command",
+		"invalid.yml:14:10: Directory `./missing-dir` set in `projects.web.dir` does not exist",
+		"invalid.yml:17:15: Invalid value for `projects.web.env.NESTED`: must be a string, a number, a boolean or a map with `value` or `sh`",
+		"invalid.yml:20:3: Missing required key `dir` in `projects.api`",
+		"invalid.yml:25:3: Unknown flag `parallel` in runner `all`. Must be `serial` or `dependent`",
+		"invalid.yml:27:7: Runner `all` references unknown command `deploy` of project `api`",
+		"invalid.yml:29:7: Runner `all` references unknown project `other`",
+		"invalid.yml:31:14: Invalid value for `runners.all[4].delay`: must be a number",
+		"invalid.yml:33:20: Invalid value for `runners.all[4].restart.condition`: must be one of `failure`, `success`, `always`",
+		"services/api.yml:2:3: Project `api` is already defined in `invalid.yml:20`",
+		"ERROR: Found 11 problem(s) in configuration",
+	)
+
+	result = tester("-f", "./dynamicenv/navi.yml", "api:fail")
+	result.AssertContains("ERROR: Failed to evaluate environment variable `TOKEN` of command `fail` in project `api`: `node -e \"console.error('not logged in'); process.exit(2)\"` failed with exit code 2: not logged in")
+	result.AssertNotContains("Executing")
+
+	result = tester("-f", "./dynamicenv/navi.yml", "api:slow")
+	result.AssertContains("ERROR: Failed to evaluate environment variable `SLOW` of command `slow` in project `api`: `node -e \"setTimeout(() => {}, 5000)\"` did not finish within 500ms")
+	os.Remove(filepath.Join(fixturesDir, "dynamicenv", "evaluations.log"))
+
+	result = tester("-f", "./inheritenv/invalid.yml", "api:check")
+	result.AssertContains("ERROR: The `inherit_env` and `unset_env` fields of command `check` in project `api` must be a boolean or a list of variable names")
+	result.AssertNotContains("Executing")
+
+	result = tester("-f", "./inheritenv/invalid.yml", "validate")
+	result.AssertContains("invalid.yml:7:15: Invalid value for `projects.api.cmds.check.inherit_env`: must be a boolean or a list of variable names")
+
+	result = tester("-f", "./layeredenv/navi.yml", "api:cycle")
+	result.AssertContains("ERROR: Failed to expand environment variables of command `cycle` in project `api`: Cyclic reference between environment variables `FIRST` -> `SECOND` -> `FIRST`")
+	result.AssertNotContains("Executing")
+
+	result = tester("-f", "./layeredenv/navi.yml", "api:dotenv-cycle")
+	result.AssertContains("ERROR: Failed to load environment file `" + filepath.Join(fixturesDir, "layeredenv", ".env.cycle") + "`: Cyclic reference between environment variables `FIRST` -> `SECOND` -> `FIRST`")
+
+	result = tester("-f", "./envfiles/navi.yml", "api:missing-key")
+	result.AssertContains("ERROR: Environment variable `service__port` not found in file `" + filepath.Join(fixturesDir, "envfiles", "values.yaml") + "`")
+
+	result = tester("-f", "./envfiles/navi.yml", "api:invalid-format")
+	result.AssertContains("Invalid format `xml`. Must be one of `dotenv`, `json`, `yaml`, `toml`")
+
+	result = tester("-f", "./envfiles/navi.yml", "validate")
+	result.AssertContains(
+		"navi.yml:30:19: Invalid value for `projects.api.cmds.invalid-format.dotenv.format`: must be one of `dotenv`, `json`, `yaml`, `toml`",
+		"ERROR: Found 1 problem(s) in configuration",
+	)
+
+	result = tester("-f", "./validate/syntax.yml", "validate")
+	result.AssertContains(
+		"syntax.yml:3:11: sequence end token ']' not found",
+		"ERROR: Found 1 problem(s) in configuration",
+	)
+
+	result = tester("-f", "./validate/fields.yml", "wrong-type")
+	result.AssertContains("ERROR: The `interactive` field for command `wrong-type` must be `true` or `false`")
+	result.AssertNotContains("Executing")
+
+	result = tester("-f", "./validate/fields.yml", "validate")
+	result.AssertContains(
+		"fields.yml:4:5: Unknown key `watc` in `commands.typo`",
+		"ERROR: Found 2 problem(s) in configuration",
+	)
+
+	result = tester("-f", "./dryrun/navi.yml", "inspect")
+	result.AssertContains("ERROR: Missing command or runner to inspect. Use `navi inspect <target>` or `navi inspect --all`")
+
+	result = tester("-f", "./dryrun/navi.yml", "inspect", "api:dev", "--format", "xml")
+	result.AssertContains("ERROR: Invalid value `xml` for `--format`. Must be one of `json`, `yaml`")
+
+	result = tester("-f", "./dryrun/navi.yml", "inspect", "api:dev", "--all")
+	result.AssertContains("ERROR: Option `--all` of `inspect` can't be combined with a command or runner")
+
+	result = tester("-f", "./dryrun/navi.yml", "inspect", "api:unknown")
+	result.AssertContains("ERROR: Command `unknown` not found in project `api`")
+
+	result = tester("-f", "./dryrun/navi.yml", "--dry-run", "api:unknown")
+	result.AssertContains("ERROR: Command `unknown` not found in project `api`")
+
 	result = tester("runner-56")
 	result.AssertContains("ERROR: Could not find `runner-56` in yaml configuration")
 
@@ -3134,6 +3435,711 @@ func TestProjectExecution(t *testing.T) {
 	}
 }
 
+func TestConfiguration(t *testing.T) {
+	var result utils.TestResult
+	tester := utils.CreateStandardTester(t, fixturesDir)
+
+	// included configuration files
+	result = tester("-f", "./include/navi.yml", "all-services")
+	result.AssertSequentialOrder(
+		"api:cwd ⟫ Executing `node cwd.js`",
+		"api:cwd ⟫ cwd => services",
+		"api:cwd ⟫ service => api",
+		"worker-cwd ⟫ Executing `node cwd.js`",
+		"worker-cwd ⟫ cwd => services",
+		"worker-cwd ⟫ service => worker",
+		"shared-cwd ⟫ Executing `node ../services/cwd.js`",
+		"shared-cwd ⟫ cwd => shared",
+		"shared-cwd ⟫ service => shared",
+		"root-cmd ⟫ Executing `node services/cwd.js`",
+		"root-cmd ⟫ cwd => include",
+	)
+
+	result = tester("-f", "./include/navi.yml", "api:cwd")
+	result.AssertContains(
+		"\ncwd => services",
+		"\nservice => api",
+		"Command(s) completed successfully",
+	)
+
+	// template variables
+	result = tester("-f", "./vars/navi.yml", "show-image")
+	result.AssertContains("\nimage => navi-app:1.2")
+
+	result = tester("-f", "./vars/navi.yml", "show-override")
+	result.AssertContains("\ntag => latest")
+
+	result = tester("-f", "./vars/navi.yml", "show-builtins")
+	result.AssertContains("\ncommand => show-builtins root => " + filepath.ToSlash(filepath.Join(fixturesDir, "vars")))
+
+	result = tester("-f", "./vars/navi.yml", "show-other-template")
+	result.AssertContains("\n{{.Names}}")
+
+	result = tester("-f", "./vars/navi.yml", "app:cwd")
+	result.AssertContains("\ncwd => service")
+
+	result = tester("-f", "./vars/navi.yml", "all")
+	result.AssertSequentialOrder(
+		"all ⟫ runner => navi-app:1.2",
+		"app:tag ⟫ tag => 1.2-app project => app command => tag",
+	)
+
+	// command parameters
+	result = tester("-f", "./params/navi.yml", "deploy", "--env=staging")
+	result.AssertContains(
+		"Executing `node params.js staging`",
+		"\nenv => staging region => eu-west-1 dry-run => ",
+	)
+
+	result = tester("-f", "./params/navi.yml", "deploy", "production", "us-east-1")
+	result.AssertContains("\nenv => production region => us-east-1 dry-run => ")
+
+	result = tester("-f", "./params/navi.yml", "deploy", "--region", "us-east-1", "staging", "--dry-run=yes")
+	result.AssertContains("\nenv => staging region => us-east-1 dry-run => yes")
+
+	result = tester("-f", "./params/navi.yml", "deploy", "staging", "--", "--verbose", "now")
+	result.AssertContains(
+		"Executing `node params.js staging --verbose now`",
+		"\nextra => --verbose now",
+	)
+
+	// extended commands
+	result = tester("-f", "./extends/navi.yml", "web:test-watch")
+	result.AssertSequentialOrder(
+		"\npre => cwd: web env: test reporter: spec service: undefined lint: undefined",
+		"\ntest --watch => cwd: web env: test reporter: spec service: undefined lint: undefined",
+	)
+
+	result = tester("-f", "./extends/navi.yml", "web:test-ci")
+	result.AssertSequentialOrder(
+		"\npre => cwd: web env: test reporter: spec",
+		"\ntest --watch => cwd: web env: test reporter: spec",
+		"\npost => cwd: web env: test reporter: spec",
+	)
+
+	result = tester("-f", "./extends/navi.yml", "web:lint")
+	result.AssertContains("\nlint => cwd: web env: undefined reporter: undefined service: undefined lint: relaxed")
+
+	result = tester("-f", "./extends/navi.yml", "api:test")
+	result.AssertContains("\ntest => cwd: api env: test reporter: dot service: api lint: undefined")
+
+	result = tester("-f", "./extends/navi.yml", "validate")
+	result.AssertContains("Configuration is valid: navi.yml")
+
+	// command dependencies
+	result = tester("-f", "./deps/navi.yml", "build-all")
+	result.AssertOccurrences("proto => started", 1)
+	result.AssertSequentialOrder("web:assets => started", "proto => finished")
+	result.AssertSequentialOrder(
+		"proto => finished",
+		"api:generate => started",
+		"api:generate => finished",
+		"api:build => started",
+		"api:build => finished",
+		"\nbuild-all => started",
+	)
+	result.AssertSequentialOrder("web:build => finished", "\nbuild-all => started")
+
+	result = tester("-f", "./deps/navi.yml", "builds")
+	result.AssertOccurrences("proto => started", 1)
+	result.AssertSequentialOrder(
+		"proto ⟫ proto => finished",
+		"api:generate ⟫ api:generate => finished",
+		"api:build ⟫ api:build => started",
+	)
+	result.AssertSequentialOrder("proto ⟫ proto => finished", "web:build ⟫ web:build => started")
+
+	result = tester("-f", "./deps/navi.yml", "overlap")
+	result.AssertOccurrences("proto => started", 1)
+	result.AssertSequentialOrder("proto => finished", "web:build ⟫ web:build => started")
+
+	result = tester("-f", "./deps/navi.yml", "validate")
+	result.AssertContains("Configuration is valid: navi.yml")
+
+	// cached commands
+	result = tester("-f", "./cache/navi.yml", "generate")
+	result.AssertContains("\ngenerated => a.txt, b.txt")
+
+	result = tester("-f", "./cache/navi.yml", "generate")
+	result.AssertContains("Sources and outputs are up to date. Skipping execution")
+	result.AssertNotContains("generated =>")
+
+	result = tester("-f", "./cache/navi.yml", "--force", "generate")
+	result.AssertContains("\ngenerated => a.txt, b.txt")
+
+	cleanUpFunctions.Add(utils.ModifyTestFile(t, filepath.Join(fixturesDir, "cache", "src", "a.txt")))
+	result = tester("-f", "./cache/navi.yml", "generate")
+	result.AssertContains("\ngenerated => a.txt, b.txt")
+	cleanUpFunctions.ExecuteAll()
+
+	result = tester("-f", "./cache/navi.yml", "generate")
+	result.AssertContains("\ngenerated => a.txt, b.txt")
+
+	cleanUpFunctions.Add(utils.DeleteTestFile(t, filepath.Join(fixturesDir, "cache", "out", "generated.txt")))
+	result = tester("-f", "./cache/navi.yml", "generate")
+	result.AssertContains("\ngenerated => a.txt, b.txt")
+	cleanUpFunctions.ExecuteAll()
+
+	result = tester("-f", "./cache/navi.yml", "hooked")
+	result.AssertSequentialOrder("\npre hook", "\ngenerated => a.txt, b.txt", "\npost hook", "\nafter hook")
+
+	result = tester("-f", "./cache/navi.yml", "hooked")
+	result.AssertContains("Sources and outputs are up to date. Skipping execution")
+	result.AssertNotContains("pre hook", "generated =>", "post hook", "after hook")
+
+	result = tester("-f", "./cache/navi.yml", "always-hooked")
+	result.AssertSequentialOrder("\ngenerated => a.txt, b.txt", "\nafter always hook")
+
+	result = tester("-f", "./cache/navi.yml", "always-hooked")
+	result.AssertContains("Sources and outputs are up to date. Skipping execution")
+	result.AssertNotContains("generated =>", "after always hook")
+
+	// sources changed by the command are compared with the content before its execution
+	result = tester("-f", "./cache/navi.yml", "self-updating")
+	result.AssertContains("\nstate updated")
+
+	result = tester("-f", "./cache/navi.yml", "self-updating")
+	result.AssertContains("\nstate updated")
+
+	result = tester("-f", "./cache/navi.yml", "self-updating")
+	result.AssertContains("Sources and outputs are up to date. Skipping execution")
+	result.AssertNotContains("state updated")
+
+	result = tester("-f", "./cache/navi.yml", "cache", "clean")
+	result.AssertContains("Removed cache directory: ")
+
+	result = tester("-f", "./cache/navi.yml", "generate")
+	result.AssertContains("\ngenerated => a.txt, b.txt")
+
+	result = tester("-f", "./cache/navi.yml", "cache", "clean")
+	result.AssertContains("Removed cache directory: ")
+	os.RemoveAll(filepath.Join(fixturesDir, "cache", ".navi"))
+	os.RemoveAll(filepath.Join(fixturesDir, "cache", "out"))
+
+	// conditional execution
+	result = tester("-f", "./conditions/navi.yml", "install")
+	result.AssertContains("\ninstall => ran")
+
+	result = tester("-f", "./conditions/navi.yml", "release-only")
+	result.AssertContains("Skipping execution: environment variable `RELEASE_TOKEN` is not set")
+	result.AssertNotContains("release-only => ran")
+
+	result = tester("-f", "./conditions/navi.yml", "production")
+	result.AssertContains("\nproduction => ran")
+
+	result = tester("-f", "./conditions/navi.yml", "native")
+	if runtime.GOOS == "linux" {
+		result.AssertContains("Skipping execution: none of the `any` conditions is met")
+		result.AssertNotContains("native => ran", "native after => ran")
+	}
+
+	result = tester("-f", "./conditions/navi.yml", "probe")
+	result.AssertSequentialOrder(
+		"Running `pre` command...",
+		"Skipping execution: environment variable `RELEASE_TOKEN` is not set",
+		"\nprobe => ran",
+	)
+	result.AssertNotContains("probe pre => ran")
+
+	os.Setenv("RELEASE_TOKEN", "secret")
+	result = tester("-f", "./conditions/navi.yml", "probe")
+	result.AssertSequentialOrder("\nprobe pre => ran", "\nprobe => ran")
+	os.Unsetenv("RELEASE_TOKEN")
+
+	result = tester("-f", "./conditions/navi.yml", "hanging")
+	result.AssertContains("Skipping execution: `node -e \"setTimeout(() => {}, 60000)\"` did not finish within 10s")
+	result.AssertNotContains("hanging => ran")
+
+	result = tester("-f", "./conditions/navi.yml", "chain")
+	result.AssertSequentialOrder(
+		"first ⟫ first => ran",
+		"release-only ⟫ Skipping execution: environment variable `RELEASE_TOKEN` is not set",
+		"last ⟫ last => ran",
+	)
+
+	result = tester("-f", "./conditions/navi.yml", "validate")
+	result.AssertContains("Configuration is valid: navi.yml")
+
+	// interactive commands
+	result = tester("-f", "./interactive/navi.yml", "ask")
+	result.AssertSequentialOrder("Executing `node ask.js`", "name? ", "\nanswer => none")
+
+	result = tester("-f", "./interactive/navi.yml", "mixed")
+	result.AssertSequentialOrder(
+		"ask ⟫ Executing `node ask.js`",
+		"\nanswer => none",
+		"ask ⟫ Command(s) completed successfully",
+		"greet ⟫ greet => ran",
+	)
+	result.AssertNotContains("ask ⟫ name?")
+
+	result = tester("-f", "./interactive/navi.yml", "validate")
+	result.AssertContains("Configuration is valid: navi.yml")
+
+	// separate and shared shell steps
+	result = tester("-f", "./steps/navi.yml", "shared")
+	result.AssertSequentialOrder(
+		"Executing `cd sub`",
+		"\nExecuting `node ../where.js after-cd`",
+		"\nafter-cd => sub",
+		"\nCommand(s) completed successfully",
+	)
+	result.AssertNotContains("Finished `")
+
+	result = tester("-f", "./steps/navi.yml", "background")
+	result.AssertSequentialOrder(
+		"parent done",
+		"Finished `node background.js 0` in ",
+		"Command(s) completed successfully",
+	)
+	result.AssertNotContains("late output")
+
+	// tolerated failures
+	result = tester("-f", "./tolerance/navi.yml", "lint")
+	result.AssertSequentialOrder(
+		"\nformat => exit 0",
+		"\nlint => exit 1",
+		"WARNING: `node exit.js lint 1` failed with exit code 1, which is tolerated",
+		"\nbuild => exit 0",
+		"WARNING: Command(s) completed with tolerated failures",
+	)
+	result.AssertNotContains("Command(s) completed successfully")
+
+	result = tester("-f", "./tolerance/navi.yml", "hooked")
+	result.AssertSequentialOrder(
+		"WARNING: `node exit.js prepare 1` failed with exit code 1, which is tolerated",
+		"\nmain => exit 0",
+		"WARNING: Command(s) completed with tolerated failures",
+	)
+
+	result = tester("-f", "./tolerance/navi.yml", "ci")
+	result.AssertSequentialOrder(
+		"lint ⟫ WARNING: Command(s) completed with tolerated failures",
+		"search ⟫ WARNING: `node exit.js grep 1` failed with exit code 1, which is tolerated",
+		"search ⟫ WARNING: Command(s) completed with tolerated failures",
+		"done ⟫ Command(s) completed successfully",
+	)
+
+	result = tester("-f", "./tolerance/navi.yml", "validate")
+	result.AssertContains("Configuration is valid: navi.yml")
+
+	// project templates
+	result = tester("-f", "./templates/navi.yml", "api:start")
+	result.AssertContains("\nstart => cwd: api env: development log: debug")
+
+	result = tester("-f", "./templates/navi.yml", "api:test")
+	result.AssertContains("\ntest => cwd: api env: test log: debug")
+
+	result = tester("-f", "./templates/navi.yml", "worker:start")
+	result.AssertContains("\nstart --queue => cwd: worker env: development log: info")
+
+	result = tester("-f", "./templates/navi.yml", "worker:drain")
+	result.AssertContains("\ndrain => cwd: worker env: development log: info")
+
+	result = tester("-f", "./templates/navi.yml", "tests")
+	result.AssertContains(
+		"api:start ⟫ start => cwd: api env: development log: debug",
+		"api:test ⟫ test => cwd: api env: test log: debug",
+	)
+
+	result = tester("-f", "./templates/navi.yml", "validate")
+	result.AssertContains("Configuration is valid: navi.yml")
+
+	// configuration profiles
+	result = tester("-f", "./profiles/navi.yml", "api:show")
+	result.AssertContains(
+		"\ncwd => local backend => local",
+		"\ndb => localhost:5432 extra => undefined",
+	)
+
+	result = tester("-f", "./profiles/navi.yml", "--profile", "docker", "api:show")
+	result.AssertContains(
+		"\ncwd => docker backend => docker",
+		"\ndb => db:5432 extra => undefined",
+	)
+
+	result = tester("-f", "./profiles/navi.yml", "-p", "docker", "all")
+	result.AssertSequentialOrder(
+		"Starting runner `all` with flags [serial] using profile `docker`",
+		"where ⟫ db => undefined:undefined extra => from-profile",
+		"api:show ⟫ cwd => docker backend => docker",
+	)
+
+	os.Setenv("NAVI_PROFILE", "staging")
+	result = tester("-f", "./profiles/navi.yml", "all")
+	result.AssertContains(
+		"Starting runner `all` using profile `staging`",
+		"api:show ⟫ cwd => local backend => staging",
+	)
+	result.AssertOccurrences("where ⟫", 0)
+	os.Unsetenv("NAVI_PROFILE")
+
+	// configuration overlays
+	result = tester("-f", "./overlay/navi.yml", "app:show")
+	result.AssertContains(
+		"Using configuration overlay: "+filepath.Join(fixturesDir, "overlay", "navi.local.yml"),
+		"\nname => app mode => shared level => debug",
+	)
+
+	result = tester("-f", "./overlay/navi.yml", "all")
+	result.AssertContains(
+		"mine ⟫ name => mine mode => undefined level => undefined",
+		"app:show ⟫ name => app mode => shared level => debug",
+	)
+	result.AssertOccurrences("greet ⟫", 0)
+
+	result = tester("-f", "./overlay/navi.yml", "-f", "./overlay/extra.yml", "app:show")
+	result.AssertSequentialOrder(
+		"Using configuration overlay: "+filepath.Join(fixturesDir, "overlay", "extra.yml"),
+		"Using configuration overlay: "+filepath.Join(fixturesDir, "overlay", "navi.local.yml"),
+		"\nname => app mode => extra level => debug",
+	)
+
+	result = tester("-f", "./overlay/navi.yml", "-f", "./overlay/extra.yml", "greet")
+	result.AssertContains("\nname => main mode => extra level => undefined")
+
+	result = tester("-f", "./overlay/navi.yml", "-f", "./overlay/extra.yml", "validate")
+	result.AssertContains("Configuration is valid: navi.yml, extra.yml, navi.local.yml")
+
+	// configuration file discovery
+	result = utils.CreateStandardTester(t, filepath.Join(fixturesDir, "include", "services"))("api:cwd")
+	result.AssertContains(
+		"Using configuration file: "+filepath.Join(fixturesDir, "include", "navi.yml"),
+		"\ncwd => services",
+	)
+
+	result = utils.CreateStandardTester(t, filepath.Join(fixturesDir, "discovery", "nested", "deep"))("where")
+	result.AssertContains(
+		"Using configuration file: "+filepath.Join(fixturesDir, "discovery", ".navi.yml"),
+		"\ncwd => discovery",
+	)
+
+	// configuration validation
+	result = tester("-f", "./validate/navi.yml", "validate")
+	result.AssertContains("Configuration is valid: navi.yml")
+
+	// dry run
+	dryRunDir := filepath.ToSlash(filepath.Join(fixturesDir, "dryrun"))
+
+	result = tester("-f", "./dryrun/navi.yml", "--dry-run", "stack")
+	result.AssertSequentialOrder(
+		"Dry run of `stack`. No command will be executed",
+		"runner: stack",
+		"  - cmd: api:build\n    serial: true\n    command:\n      id: api:build\n      dir: "+dryRunDir+"\n      shell: bash\n      shared_shell: true",
+		"        - run:\n            - node show.js compile\n            - node show.js bundle\n",
+		"        - run:\n            - node show.js lint\n",
+		"          continue_on_error: true\n      env:\n        API_URL: http://localhost:8080\n        FORCE_COLOR: \"1\"\n        LOG_LEVEL: debug\n        MODE: release\n",
+		"      env_sources:\n        API_URL: dotenv .env\n        FORCE_COLOR: navi\n        LOG_LEVEL: project env\n        MODE: command env\n      timeout: 2m",
+		"      deps:\n        - id: migrate",
+		"          if:\n            env: DATABASE_URL",
+		"      pre:\n        - level: project",
+		"                - node show.js project-pre",
+		"        - level: command",
+		"                - node show.js pre",
+		"      post:\n        - level: command",
+		"                - node show.js post",
+		"      after:\n        - level: command\n          when: success",
+		"                - node show.js deployed",
+		"        - level: command\n          when: failure",
+		"                - node show.js rollback",
+		"        - level: project",
+		"                - node show.js project-after",
+		"  - cmd: api:dev\n    name: server\n    waits_for: api:build\n    restart:\n      condition: failure\n      retries: 3\n      interval: 2.0",
+		"      watch:\n        include:\n          - "+dryRunDir+"/**/*.js\n        exclude:\n          - "+dryRunDir+"/node_modules/**",
+		"  - cmd: migrate\n    waits_for: api:build\n    dependent: true\n    delay: 1.5\n    awaits:\n      ports: [5432]\n      timeout: 10.0",
+	)
+	result.AssertNotContains("Executing", "=> ran", "Starting runner")
+
+	if runtime.GOOS != "windows" {
+		result.AssertContains(
+			"          argv: [bash, -c, echo naviLogId",
+			"_1 && node show.js compile && echo naviLogId",
+			"_2 && node show.js bundle]",
+		)
+	}
+
+	result = tester("-f", "./dryrun/navi.yml", "--dry-run", "-s", "api:dev", "migrate")
+	result.AssertSequentialOrder(
+		"runner: inline\nserial: true",
+		"  - cmd: api:dev\n    serial: true",
+		"  - cmd: migrate\n    waits_for: api:dev\n    serial: true",
+	)
+	result.AssertNotContains("=> ran")
+
+	result = tester("-f", "./dryrun/navi.yml", "--dry-run", "api:dev")
+	result.AssertContains("\nid: api:dev\n", "\nwatch:\n")
+	result.AssertNotContains("=> ran", "Starting in watch mode")
+
+	// inspect output
+	result = tester("-f", "./dryrun/navi.yml", "inspect", "api:build")
+	result.AssertSequentialOrder(
+		"{\n  \"version\": 1,\n  \"commands\": [\n    {\n      \"id\": \"api:build\",\n      \"dir\": \""+dryRunDir+"\",\n      \"shell\": \"bash\",\n      \"shared_shell\": true,",
+		"          \"run\": [\n            \"node show.js lint\"\n          ],",
+		"          \"continue_on_error\": true",
+		"      \"env_sources\": {\n        \"API_URL\": \"dotenv .env\",\n        \"FORCE_COLOR\": \"navi\",\n        \"LOG_LEVEL\": \"project env\",\n        \"MODE\": \"command env\"\n      },",
+		"      \"deps\": [\n        {\n          \"id\": \"migrate\",",
+		"      \"after\": [\n        {\n          \"level\": \"command\",\n          \"when\": \"success\",",
+		"  \"runners\": []\n}",
+	)
+	result.AssertNotContains("=> ran")
+
+	result = tester("-f", "./dryrun/navi.yml", "inspect", "--format", "yaml", "stack")
+	result.AssertSequentialOrder(
+		"version: 1\ncommands: []\nrunners:\n  - runner: stack\n    commands:\n      - cmd: api:build",
+		"      - cmd: migrate\n        waits_for: api:build\n        dependent: true\n        delay: 1.5\n        awaits:\n          ports: [5432]\n          timeout: 10.0",
+	)
+
+	result = tester("-f", "./dryrun/navi.yml", "inspect", "--all", "--format=yaml")
+	result.AssertSequentialOrder(
+		"version: 1\ncommands:\n  - id: migrate",
+		"  - id: api:build",
+		"  - id: api:dev",
+		"runners:\n  - runner: stack",
+	)
+	result.AssertNotContains("errors:")
+
+	result = tester("-f", "./params/navi.yml", "inspect", "--all")
+	result.AssertContains(
+		"  \"errors\": [\n    {\n      \"target\": \"deploy\",\n      \"error\": \"Missing required parameter `env` for command `deploy`\"",
+	)
+
+	// secret masking
+	result = tester("-f", "./secrets/navi.yml", "api:show")
+	result.AssertSequentialOrder(
+		"DB_PASSWORD = ***",
+		"API_KEY = ***",
+		"LOG_LEVEL = debug",
+	)
+	result.AssertNotContains("s3cr3t-password", "key-0123456789")
+
+	result = tester("-f", "./secrets/navi.yml", "api:stderr")
+	result.AssertContains("key ***")
+	result.AssertNotContains("key-0123456789")
+
+	result = tester("-f", "./secrets/navi.yml", "api:partial")
+	result.AssertContains("API_KEY = ***")
+
+	result = tester("-f", "./secrets/navi.yml", "bare")
+	result.AssertContains("API_KEY = ***")
+
+	result = tester("-f", "./secrets/navi.yml", "api:short")
+	result.AssertContains(
+		"WARNING: Secret `CI_TOKEN` is not masked in the output, since its value is shorter than 4 characters or too generic",
+		"STAGE = ***",
+		"CI_TOKEN = none",
+	)
+	result.AssertNotContains("Secret `STAGE` is not masked", "STAGE = dev")
+
+	os.Setenv("DEPLOY_TOKEN", "tok-abcdef")
+	result = tester("-f", "./secrets/navi.yml", "api:deploy")
+	result.AssertContains(
+		"Executing `node show.js ***`",
+		"*** = ***",
+	)
+	result.AssertNotContains("tok-abcdef")
+
+	result = tester("-f", "./secrets/navi.yml", "api:fail")
+	result.AssertContains("Finished `node -e \"process.exit(3)\" ***`")
+	result.AssertNotContains("tok-abcdef")
+
+	result = tester("-f", "./secrets/navi.yml", "--dry-run", "api:deploy")
+	result.AssertContains(
+		"node show.js ***]",
+		"  API_KEY: \"***\"\n  DB_PASSWORD: \"***\"",
+	)
+	result.AssertNotContains("tok-abcdef", "s3cr3t-password", "key-0123456789")
+
+	result = tester("-f", "./secrets/navi.yml", "inspect", "api:deploy")
+	result.AssertContains("\"DB_PASSWORD\": \"***\"")
+	result.AssertNotContains("tok-abcdef", "s3cr3t-password", "key-0123456789")
+	os.Unsetenv("DEPLOY_TOKEN")
+
+	// structured environment files
+	result = tester("-f", "./envfiles/navi.yml", "api:json")
+	result.AssertSequentialOrder(
+		"DB__HOST = localhost",
+		"DB__PORT = 5432",
+		"FEATURES__1 = export",
+		"MAX_UPLOAD = 10485760",
+	)
+
+	result = tester("-f", "./envfiles/navi.yml", "api:yaml")
+	result.AssertSequentialOrder(
+		"service_name = api",
+		"service_replicas = 3",
+		"debug = true",
+	)
+
+	result = tester("-f", "./envfiles/navi.yml", "api:toml")
+	result.AssertSequentialOrder(
+		"region = eu-west-1",
+		"cache__ttl = 300",
+	)
+
+	result = tester("-f", "./envfiles/navi.yml", "api:keys")
+	result.AssertSequentialOrder(
+		"DB__HOST = localhost",
+		"DB__PORT = 5432",
+		"MAX_UPLOAD = undefined",
+	)
+
+	result = tester("-f", "./envfiles/navi.yml", "api:format")
+	result.AssertContains("QUEUE__NAME = jobs")
+
+	result = tester("-f", "./envfiles/navi.yml", "--dry-run", "api:toml")
+	result.AssertContains("  cache__ttl: dotenv settings.toml")
+
+	// dynamic environment variables
+	result = tester("-f", "./dynamicenv/navi.yml", "api:context")
+	result.AssertSequentialOrder(
+		"CWD = sub",
+		"ZONE = eu-1",
+	)
+
+	result = tester("-f", "./dynamicenv/navi.yml", "api:secret")
+	result.AssertContains("TOKEN = ***")
+	result.AssertNotContains("tok-generated")
+
+	evaluationsLog := filepath.Join(fixturesDir, "dynamicenv", "evaluations.log")
+	os.Remove(evaluationsLog)
+
+	// The commands are shown, not executed, by --dry-run and inspect
+	result = tester("-f", "./dynamicenv/navi.yml", "--dry-run", "api:show")
+	result.AssertContains("  BUILD_ID: \"sh: node build-id.js\"")
+	result = tester("-f", "./dynamicenv/navi.yml", "inspect", "api:show")
+	result.AssertContains("\"BUILD_ID\": \"sh: node build-id.js\"")
+
+	result = tester("-f", "./dynamicenv/navi.yml", "build")
+	result.AssertSequentialOrder(
+		"api:show ⟫ BUILD_ID = build-42",
+		"api:show-again ⟫ BUILD_ID = build-42",
+		"api:evaluations ⟫ evaluations = 1",
+	)
+	os.Remove(evaluationsLog)
+
+	result = tester("-f", "./dynamicenv/navi.yml", "filtered")
+	result.AssertContains("filtered:isolated ⟫ HOME_SEEN = unset")
+	result.AssertNotContains("filtered:inherited ⟫ HOME_SEEN = unset")
+
+	// Commands of parallel entries are evaluated at the same time
+	result = tester("-f", "./dynamicenv/navi.yml", "parallel")
+	result.AssertContains(
+		"parallel:first ⟫ PEER = first met second",
+		"parallel:second ⟫ PEER = second met first",
+	)
+	os.Remove(filepath.Join(fixturesDir, "dynamicenv", "first.flag"))
+	os.Remove(filepath.Join(fixturesDir, "dynamicenv", "second.flag"))
+
+	// inherited environment
+	os.Setenv("STRAY_FLAG", "1")
+	os.Setenv("ALLOWED_FLAG", "1")
+	result = tester("-f", "./inheritenv/navi.yml", "global")
+	result.AssertSequentialOrder(
+		"STRAY_FLAG is not set",
+		"ALLOWED_FLAG is set",
+		"FORCE_COLOR is not set",
+	)
+
+	result = tester("-f", "./inheritenv/navi.yml", "raw")
+	result.AssertSequentialOrder(
+		"STRAY_FLAG is not set",
+		"ALLOWED_FLAG is set",
+		"FORCE_COLOR is not set",
+	)
+
+	result = tester("-f", "./inheritenv/navi.yml", "api:check")
+	result.AssertSequentialOrder(
+		"PATH is set",
+		"ALLOWED_FLAG is set",
+		"STRAY_FLAG is not set",
+		"FORCE_COLOR is not set",
+		"DEBUG_LEVEL is not set",
+	)
+
+	result = tester("-f", "./inheritenv/navi.yml", "api:open")
+	result.AssertSequentialOrder(
+		"Running `pre` command...",
+		"STRAY_FLAG is set",
+		"Running main command...",
+		"STRAY_FLAG is set",
+		"DEBUG_LEVEL is not set",
+	)
+
+	result = tester("-f", "./inheritenv/navi.yml", "api:guarded")
+	result.AssertContains("Skipping execution: environment variable `STRAY_FLAG` is not set")
+
+	result = tester("-f", "./inheritenv/navi.yml", "--dry-run", "api:check")
+	result.AssertContains(
+		"inherit_env:\n  - PATH\n  - ALLOWED_*",
+		"unset_env:\n  - FORCE_COLOR\n  - DEBUG_*",
+	)
+	os.Unsetenv("STRAY_FLAG")
+	os.Unsetenv("ALLOWED_FLAG")
+
+	// layered environment variables
+	os.Setenv("LAYER_REGION", "eu")
+	result = tester("-f", "./layeredenv/navi.yml", "api:project")
+	result.AssertSequentialOrder(
+		"DATABASE_URL = postgres://admin@localhost:5432/app",
+		"REGION_LABEL = region-eu",
+	)
+	os.Unsetenv("LAYER_REGION")
+
+	result = tester("-f", "./layeredenv/navi.yml", "api:command")
+	result.AssertSequentialOrder(
+		"Running `pre` command...",
+		"API_URL = http://db.internal/api",
+		"Running main command...",
+		"API_URL = http://db.internal/api",
+		"CONNECTION = postgres://admin@localhost:5432/app",
+		"LITERAL = ${DB_USER}",
+		"GREETING = hello admin",
+		"DB_PORT = 54321",
+	)
+
+	// JSON schema
+	result = tester("schema")
+	result.AssertContains(
+		`"$schema": "https://json-schema.org/draft/2020-12/schema"`,
+		`"runnerCommand": {`,
+		`"pattern": "^[^\\[\\]]+(\\[(\\s*(serial|dependent)\\s*(,\\s*(serial|dependent)\\s*)*)?\\])?$"`,
+		`"pattern": "^[^|]+(\\|[^|]+)?$"`,
+	)
+
+	os.Setenv("NAVI_CONFIG", "./include/navi.yml")
+	result = tester("root-cmd")
+	result.AssertContains(
+		"Using configuration file: "+filepath.Join(fixturesDir, "include", "navi.yml"),
+		"\ncwd => include",
+	)
+	os.Unsetenv("NAVI_CONFIG")
+
+	// unknown command fields
+	result = tester("-f", "./validate/fields.yml", "typo")
+	result.AssertSequentialOrder(
+		"WARNING: Ignoring unknown field `watc` for command `typo`. Must be one of `extends`, `dir`, `shell`, `watch`",
+		"\ntypo",
+	)
+
+	// names of built-in commands
+	result = tester("-f", "./builtins/navi.yml", "hello")
+	result.AssertContains("hello")
+	result.AssertNotContains("ERROR")
+
+	result = tester("-f", "./builtins/navi.yml", "cache:clean")
+	result.AssertContains("cleaned")
+	result.AssertNotContains("ERROR")
+
+	result = tester("-f", "./builtins/navi.yml", "validate")
+	result.AssertContains(
+		"navi.yml:2:3: warning: Command `inspect` can't be run as `navi inspect`, which runs the built-in command instead",
+		"navi.yml:6:3: warning: Project `cache` can't be run as `navi cache clean`, which runs the built-in command instead",
+		"navi.yml:12:3: warning: Runner `validate` can't be run as `navi validate`, which runs the built-in command instead",
+		"Configuration is valid",
+	)
+	result.AssertNotContains("ERROR")
+}
+
 func TestCLI(t *testing.T) {
 	var result utils.TestResult
 	tester := utils.CreateCLITester(t, fixturesDir)
@@ -3781,6 +4787,43 @@ func TestCLI(t *testing.T) {
 		"[Enter] Execute   [Ctrl+Space] Execute w/ args   [Tab]...",
 	)
 
+	result = utils.CreateCLITester(t, fixturesDir, "-f", "./profiles/navi.yml", "-p", "docker")("cli-profile", 80, 12, term.TEST_SNAPSHOT)
+	result.AssertSequentialOrder(
+		"                               Navi CLI [docker]",
+		"--------------------------------------------------------------------------------",
+		"Filter:                       [1 of 3] ¦ Definition:",
+		"                                       ¦",
+		"where                          Command ¦ where:",
+		"api:show                       Project ¦   env:",
+		"all[serial]                     Runner ¦     EXTRA: from-profile",
+		"                                       ¦   run: node show.js {{ .vars.backend }}",
+	)
+
+	result = utils.CreateCLITester(t, fixturesDir, "-f", "./overlay/navi.yml", "-f", "./overlay/extra.yml")("cli-overlay", 80, 14, "app:show", term.TEST_SNAPSHOT)
+	result.AssertSequentialOrder(
+		"app:show                       Project ¦ app:",
+		"                                       ¦   dir: ./app",
+		"                                       ¦   env:",
+		"                                       ¦     LEVEL: debug  # navi.local.yml",
+		"                                       ¦     MODE: extra  # extra.yml",
+	)
+
+	result = utils.CreateCLITester(t, fixturesDir, "-f", "./templates/navi.yml")("cli-templates", 80, 16, term.TEST_SNAPSHOT)
+	result.AssertSequentialOrder(
+		"api:start                      Project ¦ api:",
+		"api:test                       Project ¦   dir: ./api",
+		"worker:drain                   Project ¦   env:",
+		"worker:start                   Project ¦     LOG_LEVEL: debug",
+		"worker:test                    Project ¦     NODE_ENV: development",
+		"tests                           Runner ¦   template: node-service",
+	)
+
+	result = utils.CreateCLITester(t, fixturesDir, "-f", "./params/navi.yml")("cli-params", 80, 14, term.KEY_CTRL_SPACE, "staging", term.KEY_DOWN, "us-east-1", term.KEY_ENTER)
+	result.AssertContains(
+		"Selected `deploy --env=staging --region=us-east-1`",
+		"env => staging region => us-east-1 dry-run => ",
+	)
+
 	result = utils.CreateCLITester(t, fixturesDir, "-f", "./yml/test_2.yml")("snapshot-23", 80, 14, term.TEST_SNAPSHOT)
 	result.AssertSequentialOrder(
 		"                                   Navi CLI",